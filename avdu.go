@@ -3,14 +3,13 @@
 package avdu
 
 import (
+	"context"
 	"encoding/base32"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
-	"regexp"
 	"time"
 
 	"github.com/sammy-t/avdu/otp"
@@ -19,76 +18,32 @@
 
 const defPeriod int64 = 30 // The default TOTP refresh interval
 
-// FindVaultPath returns the most recently modified
-// vault's filepath.
+// FindVaultPath returns the filepath of the vault
+// with the newest file name timestamp.
 func FindVaultPath(vaultDir string) (string, error) {
-	var vaultPath string
-
-	files, err := os.ReadDir(vaultDir)
-	if err != nil || len(files) == 0 {
-		return vaultPath, err
-	}
-
-	vaultFile, err := LastModified(files)
-	if err != nil {
-		return vaultPath, err
-	}
-
-	if vaultFile == nil {
-		return vaultPath, errors.New("no vault backup or export file found")
-	}
-
-	vaultPath = fmt.Sprintf("%v/%v", vaultDir, vaultFile.Name())
-
-	return vaultPath, nil
+	return FindVaultPathWith(vaultDir, SelectFilenameTime, 0)
 }
 
 // ReadVaultFile parses the json file at the path
 // and returns a plaintext vault.
 func ReadVaultFile(filePath string) (*vault.Vault, error) {
-	var vault vault.Vault
-
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal(data, &vault)
-	if err != nil {
-		return nil, err
-	}
-
-	// A simple check to see if the parsed vault is valid
-	if vault.Version == 0 {
-		err = fmt.Errorf("invalid or empty vault")
-		return nil, err
-	}
-
-	return &vault, nil
+	return parseVault(data)
 }
 
 // ReadVaultFileEnc parses the json file at the path
 // and returns an encrypted vault.
 func ReadVaultFileEnc(filePath string) (*vault.VaultEncrypted, error) {
-	var vault vault.VaultEncrypted
-
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal(data, &vault)
-	if err != nil {
-		return nil, err
-	}
-
-	// A simple check to see if the parsed vault is valid
-	if vault.Version == 0 {
-		err = fmt.Errorf("invalid or empty vault")
-		return nil, err
-	}
-
-	return &vault, nil
+	return parseVaultEnc(data)
 }
 
 // ReadAndDecryptVaultFile parses the json file at the path,
@@ -112,10 +67,24 @@ func ReadAndDecryptVaultFile(filePath string, pwd string) (*vault.Vault, error)
 	return vaultDataPlain, nil
 }
 
-// LastModified finds the most recent vault file.
-func LastModified(files []fs.DirEntry) (fs.DirEntry, error) {
-	vaultFileRE := regexp.MustCompile(`^aegis-(backup|export)-\d+(-\d+)*\.json$`)
+// ReadAndDecryptVaultFileContext parses the json file at the path,
+// decrypts the vault content, and returns a plaintext vault.
+//
+// The password slots are tried in parallel as configured by the options
+// and the context's cancellation stops the search for the master key.
+func ReadAndDecryptVaultFileContext(ctx context.Context, filePath string, pwd string, opts vault.KeyOptions) (*vault.Vault, error) {
+	vaultDataEnc, err := ReadVaultFileEnc(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var file *VaultFile = &VaultFile{enc: vaultDataEnc}
+
+	return file.UnlockContext(ctx, pwd, opts)
+}
 
+// LastModified finds the most recently modified vault file.
+func LastModified(files []fs.DirEntry) (fs.DirEntry, error) {
 	var vaultFile fs.DirEntry
 	var err error
 
@@ -160,53 +129,97 @@ func lastModTime(file1 fs.DirEntry, file2 fs.DirEntry) (fs.DirEntry, error) {
 
 // GetOTP generates an OTP from the provided entry data.
 func GetOTP(entry vault.Entry) (otp.OTP, error) {
-	secretData, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(entry.Info.Secret)
+	var secretData []byte
+	var err error
+
+	if entry.Type == "motp" {
+		secretData, err = hex.DecodeString(entry.Info.Secret)
+	} else {
+		secretData, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(entry.Info.Secret)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	var pass otp.OTP
+	return generateOTP(entry.Type, secretData, entry.Info.Algo, entry.Info.Digits, int64(entry.Info.Period), entry.Info.Pin)
+}
 
-	switch entry.Type {
+// generateOTP is a helper to generate an OTP of the type from the decoded secret.
+func generateOTP(otpType string, secret []byte, algo string, digits int, period int64, pin string) (otp.OTP, error) {
+	switch otpType {
 	case "totp":
-		pass, err = otp.GenerateTOTP(secretData, entry.Info.Algo, entry.Info.Digits, int64(entry.Info.Period))
+		return otp.GenerateTOTP(secret, algo, digits, period)
 	case "hotp":
-		pass = otp.HOTP{}
+		return otp.HOTP{}, nil
 	case "steam":
-		pass, err = otp.GenerateSteamOTP(secretData, entry.Info.Algo, entry.Info.Digits, int64(entry.Info.Period))
+		return otp.GenerateSteamOTP(secret, algo, digits, period)
 	case "motp":
-		secretData, err = hex.DecodeString(entry.Info.Secret)
+		return otp.GenerateMOTP(secret, algo, digits, period, pin)
+	default:
+		return nil, fmt.Errorf("unsupported otp type %q", otpType)
+	}
+}
+
+// OTPResult holds either the OTP generated for an entry
+// or the error that prevented generating it.
+type OTPResult struct {
+	OTP otp.OTP
+	Err error
+}
+
+// EntryError describes an error generating an entry's OTP.
+type EntryError struct {
+	Uuid   string
+	Issuer string
+	Name   string
+	Err    error
+}
+
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("entry %v (%v): %v", e.Issuer, e.Name, e.Err)
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// GetOTPResults generates OTPs for the entries in the vault
+// and returns a map matching each entry's uuid and result.
+//
+// Each failed entry's result contains an *EntryError and the returned error
+// joins the errors of every failed entry.
+func GetOTPResults(vaultData *vault.Vault) (map[string]OTPResult, error) {
+	var results map[string]OTPResult = make(map[string]OTPResult)
+	var errs []error
+
+	for _, entry := range vaultData.Db.Entries {
+		pass, err := GetOTP(entry)
 		if err != nil {
-			return nil, err
+			err = &EntryError{Uuid: entry.Uuid, Issuer: entry.Issuer, Name: entry.Name, Err: err}
+			errs = append(errs, err)
 		}
 
-		pass, err = otp.GenerateMOTP(secretData, entry.Info.Algo, entry.Info.Digits, int64(entry.Info.Period), entry.Info.Pin)
-	default:
-		err = fmt.Errorf("unsupported otp type %q", entry.Type)
+		results[entry.Uuid] = OTPResult{OTP: pass, Err: err}
 	}
 
-	return pass, err
+	return results, errors.Join(errs...)
 }
 
 // GetOTPs generates OTPs for the entries in the vault
 // and returns a map matching each entry's uuid and OTP.
 //
 // If there's an error, the successfully generated OTPs will
-// be returned along with the error.
+// be returned along with the joined errors of the failed entries.
 func GetOTPs(vaultData *vault.Vault) (map[string]otp.OTP, error) {
-	var entries []vault.Entry = vaultData.Db.Entries
+	results, err := GetOTPResults(vaultData)
 
 	var otps map[string]otp.OTP = make(map[string]otp.OTP)
-	var err error
 
-	for _, entry := range entries {
-		pass, passErr := GetOTP(entry)
-		if passErr != nil {
-			err = passErr
-			continue
+	for uuid, result := range results {
+		if result.Err == nil {
+			otps[uuid] = result.OTP
 		}
-
-		otps[entry.Uuid] = pass
 	}
 
 	return otps, err
@@ -223,3 +236,107 @@ func GetTTNPer(period int64) int64 {
 
 	return p - (time.Now().UnixMilli() % p)
 }
+
+// NextRefresh calculates the time in millis until the OTP of any of
+// the entries refreshes, using each entry's period.
+func NextRefresh(entries []vault.Entry) int64 {
+	next, _ := NextRefreshAt(entries, time.Now().UnixMilli())
+
+	return next
+}
+
+// NextRefreshAt calculates the time in millis from the Unix time in millis
+// until the OTP of any of the entries refreshes, using each entry's period.
+// It also returns the period in millis of the OTP that refreshes first,
+// preferring the shorter period when several refresh at the same time.
+//
+// HOTP entries don't refresh. The default period is used for entries
+// without a period and when no entry refreshes.
+func NextRefreshAt(entries []vault.Entry, ms int64) (int64, int64) {
+	var next int64
+	var nextPeriod int64
+
+	for _, entry := range entries {
+		if entry.Type == "hotp" {
+			continue
+		}
+
+		var period int64 = int64(entry.Info.Period)
+
+		if period <= 0 {
+			period = defPeriod
+		}
+
+		var p int64 = period * 1000
+		var ttn int64 = p - (ms % p)
+
+		if next == 0 || ttn < next || (ttn == next && p < nextPeriod) {
+			next = ttn
+			nextPeriod = p
+		}
+	}
+
+	if next == 0 {
+		var p int64 = defPeriod * 1000
+
+		return p - (ms % p), p
+	}
+
+	return next, nextPeriod
+}
+
+// keyCache reads plaintext and encrypted vault files, reusing the master key
+// from the last decrypted vault while it continues to decrypt later files.
+type keyCache struct {
+	pwd       string
+	masterKey []byte
+}
+
+// read is a helper to read the vault file, decrypting it
+// with the cached master key or the password if necessary.
+func (c *keyCache) read(filePath string) (*vault.Vault, error) {
+	file, err := Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.unlock(file)
+}
+
+// unlock is a helper to return the opened file's plaintext vault, decrypting it
+// with the cached master key or the password if necessary.
+func (c *keyCache) unlock(file *VaultFile) (*vault.Vault, error) {
+	if !file.Encrypted() {
+		return file.Vault()
+	}
+
+	if c.masterKey != nil {
+		vaultData, err := file.UnlockWithKey(c.masterKey)
+		if err == nil {
+			return vaultData, nil
+		}
+	}
+
+	vaultData, err := file.Unlock(c.pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setKey(file.masterKey)
+
+	return vaultData, nil
+}
+
+// setKey is a helper to cache the master key, wiping the one it replaces.
+func (c *keyCache) setKey(masterKey []byte) {
+	if c.masterKey != nil && (masterKey == nil || &c.masterKey[0] != &masterKey[0]) {
+		vault.Wipe(c.masterKey)
+	}
+
+	c.masterKey = masterKey
+}
+
+// wipe is a helper to wipe the cached master key once it's no longer needed.
+func (c *keyCache) wipe() {
+	c.setKey(nil)
+}
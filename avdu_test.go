@@ -0,0 +1,74 @@
+package avdu_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestGetOTPResults(t *testing.T) {
+	vaultData, err := avdu.ReadVaultFile("test/data/aegis_plain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var failed vault.Entry = vaultData.Db.Entries[1]
+
+	vaultData.Db.Entries[1].Info.Secret = "not base32!"
+
+	results, err := avdu.GetOTPResults(vaultData)
+
+	var entryErr *avdu.EntryError
+
+	if !errors.As(err, &entryErr) || entryErr.Uuid != failed.Uuid || entryErr.Issuer != failed.Issuer || entryErr.Name != failed.Name {
+		t.Fatalf("GetOTPResults() error = %v; want an *EntryError for %v (%v)", err, failed.Issuer, failed.Name)
+	}
+
+	for i, entry := range vaultData.Db.Entries {
+		var result avdu.OTPResult = results[entry.Uuid]
+
+		if failed := entry.Uuid == failed.Uuid; failed != (result.Err != nil) || failed == (result.OTP != nil) {
+			t.Fatalf("[%v] GetOTPResults() = %v, %v; want failure %v", i, result.OTP, result.Err, failed)
+		}
+	}
+
+	otps, err := avdu.GetOTPs(vaultData)
+
+	if err == nil || len(otps) != len(vaultData.Db.Entries)-1 {
+		t.Fatalf("GetOTPs() = %v OTPs, %v; want %v OTPs and an error", len(otps), err, len(vaultData.Db.Entries)-1)
+	}
+}
+
+func TestNextRefreshAt(t *testing.T) {
+	var totp20 vault.Entry = vault.Entry{Type: "totp", Info: vault.Info{Period: 20}}
+	var totp30 vault.Entry = vault.Entry{Type: "totp", Info: vault.Info{Period: 30}}
+	var steam vault.Entry = vault.Entry{Type: "steam"}
+	var hotp vault.Entry = vault.Entry{Type: "hotp"}
+
+	type refreshTest struct {
+		entries []vault.Entry
+		ms      int64
+		want    int64
+		period  int64
+	}
+
+	var vectors []refreshTest = []refreshTest{
+		{[]vault.Entry{totp30}, 0, 30000, 30000},
+		{[]vault.Entry{totp30}, 25500, 4500, 30000},
+		{[]vault.Entry{totp20, totp30}, 25000, 5000, 30000},
+		{[]vault.Entry{totp20, totp30}, 41000, 19000, 20000},
+		{[]vault.Entry{steam}, 1000, 29000, 30000},
+		{[]vault.Entry{totp30, totp20}, 0, 20000, 20000},
+		{[]vault.Entry{totp30, totp20}, 60000, 20000, 20000},
+		{[]vault.Entry{hotp}, 10000, 20000, 30000},
+		{nil, 59999, 1, 30000},
+	}
+
+	for i, vector := range vectors {
+		if got, period := avdu.NextRefreshAt(vector.entries, vector.ms); got != vector.want || period != vector.period {
+			t.Fatalf("[%v] NextRefreshAt(%v) = %v, %v; want %v, %v", i, vector.ms, got, period, vector.want, vector.period)
+		}
+	}
+}
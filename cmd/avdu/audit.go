@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+func auditAction(ctx *cli.Context) error {
+	var path string = ctx.Path("path")
+	var pwd string
+
+	vaultData, err := readAnyVault(path, &pwd)
+	if err != nil {
+		return fmt.Errorf("cannot read vault %q: %w", path, err)
+	}
+
+	var report vault.AuditReport = vault.Audit(vaultData)
+
+	if ctx.Bool("json") {
+		output, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return fmt.Errorf("cannot marshal report: %w", err)
+		}
+
+		fmt.Println(string(output))
+	} else {
+		fmt.Println(report)
+	}
+
+	if report.Score < ctx.Int("min-score") {
+		return cli.Exit("", 1)
+	}
+
+	return nil
+}
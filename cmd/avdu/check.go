@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+func checkAction(ctx *cli.Context) error {
+	var path string = ctx.Path("path")
+	var problems []vault.Problem
+
+	vaultData, vaultDataEnc, err := readVaultInput(path)
+	if err != nil {
+		return fmt.Errorf("cannot read vault %q: %w", path, err)
+	}
+
+	if vaultDataEnc != nil {
+		problems = append(problems, vault.ValidateHeader(vaultDataEnc.Header)...)
+
+		vaultData, _, err = decryptInput(vaultDataEnc)
+		if err != nil {
+			return fmt.Errorf("cannot decrypt vault %q: %w", path, err)
+		}
+
+		// The header was already checked as an encrypted header
+		vaultData.Header = vault.Header{}
+	}
+
+	problems = append(problems, vault.Validate(vaultData)...)
+
+	if ctx.Bool("json") {
+		output, err := json.MarshalIndent(problems, "", "    ")
+		if err != nil {
+			return fmt.Errorf("cannot marshal problems: %w", err)
+		}
+
+		fmt.Println(string(output))
+	} else {
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+
+		fmt.Printf("%v problems found in %v entries\n", len(problems), len(vaultData.Db.Entries))
+	}
+
+	if vault.HasErrors(problems) {
+		return cli.Exit("", 1)
+	}
+
+	return nil
+}
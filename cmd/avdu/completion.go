@@ -0,0 +1,435 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// completeCommand is the hidden command the completion scripts call for candidates.
+const completeCommand string = "__complete"
+
+// nameCacheFile is the file in the user's cache directory holding entry names for completion.
+const nameCacheFile string = "names.json"
+
+// nameCache holds the entry metadata of the most recently unlocked vault.
+//
+// It's stored unencrypted so completion never needs the vault password.
+type nameCache struct {
+	Vault   string               `json:"vault"`
+	Entries []avdu.EntryMetadata `json:"entries"`
+}
+
+var cacheNamesFlag = &cli.BoolFlag{
+	Name:  "cache-names",
+	Usage: "save entry issuers, names, and groups (never secrets) unencrypted for shell completion",
+}
+
+var writeIndexFlag = &cli.StringFlag{
+	Name:  "write-index",
+	Usage: "write an index of entry names (never secrets) next to an encrypted vault file (encrypted, plain)",
+}
+
+// completionScripts maps each supported shell to its completion script.
+var completionScripts map[string]string = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+const bashCompletion string = `# bash completion for avdu
+_avdu_complete() {
+    local IFS=$'\n' candidate
+    COMPREPLY=()
+
+    # Quote the candidates so names with spaces are inserted as a single word
+    for candidate in $(avdu __complete -- "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null); do
+        COMPREPLY+=("$(printf '%q' "$candidate")")
+    done
+}
+
+complete -o default -F _avdu_complete avdu
+`
+
+const zshCompletion string = `#compdef avdu
+# zsh completion for avdu
+_avdu() {
+    local -a candidates
+    candidates=("${(@f)$(avdu __complete -- "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=(${candidates:#})
+
+    if (( ${#candidates} )); then
+        compadd -U -Q -- "${(@q)candidates}"
+    else
+        _files
+    fi
+}
+
+compdef _avdu avdu
+`
+
+const fishCompletion string = `# fish completion for avdu
+function __avdu_complete
+    set -l candidates (avdu __complete -- (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)
+
+    if test (count $candidates) -eq 0
+        __fish_complete_path (commandline -ct)
+    else
+        printf '%s\n' $candidates
+    end
+end
+
+complete -c avdu -f -a '(__avdu_complete)'
+`
+
+// completionAction outputs the completion script for a shell.
+func completionAction(ctx *cli.Context) error {
+	var shell string = ctx.Args().First()
+
+	script, ok := completionScripts[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q (want bash, zsh, or fish)", shell)
+	}
+
+	fmt.Print(script)
+
+	return nil
+}
+
+// completeAction outputs a completion candidate per line for the command line words.
+//
+// The last word is the one being completed and may be empty.
+// No output lets the shell fall back to completing file paths.
+func completeAction(ctx *cli.Context) error {
+	var words []string = ctx.Args().Slice()
+
+	if len(words) > 0 && words[0] == "--" {
+		words = words[1:]
+	}
+
+	for _, candidate := range completeWords(ctx.App, words) {
+		fmt.Println(candidate)
+	}
+
+	return nil
+}
+
+// completeWords is a helper to find the candidates for the last word
+// by walking the commands and flags of the preceding words.
+func completeWords(app *cli.App, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	// Shells pass the words as typed, including quotes and escapes
+	words = slices.Clone(words)
+
+	for i, word := range words {
+		words[i] = unquoteWord(word)
+	}
+
+	var commands []*cli.Command = app.Commands
+	var flags []cli.Flag = app.Flags
+	var command *cli.Command
+
+	var current string = words[len(words)-1]
+	var valueFlag cli.Flag
+	var vaultPath string
+
+	for _, word := range words[:len(words)-1] {
+		if valueFlag != nil {
+			if valueFlag.Names()[0] == "path" {
+				vaultPath = word
+			}
+
+			valueFlag = nil
+			continue
+		}
+
+		if strings.HasPrefix(word, "-") {
+			var name string = strings.TrimLeft(word, "-")
+
+			if strings.Contains(name, "=") {
+				continue
+			}
+
+			if flag := findFlag(flags, name); flag != nil && takesValue(flag) {
+				valueFlag = flag
+			}
+
+			continue
+		}
+
+		if sub := findCommand(commands, word); sub != nil {
+			command = sub
+			commands = sub.Subcommands
+			flags = sub.Flags
+		}
+	}
+
+	if valueFlag != nil {
+		return matchPrefix(flagValues(valueFlag, vaultPath), current)
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var names []string
+
+		for _, flag := range flags {
+			for _, name := range flag.Names() {
+				if len(name) == 1 {
+					names = append(names, "-"+name)
+				} else {
+					names = append(names, "--"+name)
+				}
+			}
+		}
+
+		return matchPrefix(names, current)
+	}
+
+	if command != nil && command.Name == "completion" {
+		return matchPrefix(slices.Sorted(maps.Keys(completionScripts)), current)
+	}
+
+	// Entry commands take an issuer or name argument
+	if command != nil && (command.Name == "show" || command.Name == "code") {
+		return matchPrefix(cachedNames(vaultPath, entryNames), current)
+	}
+
+	var names []string
+
+	for _, sub := range commands {
+		if !sub.Hidden {
+			names = append(names, sub.Names()...)
+		}
+	}
+
+	return matchPrefix(names, current)
+}
+
+// flagValues is a helper to list the known values of a flag.
+// Entry names come from the vault file's plaintext index if it's current,
+// otherwise from the name cache.
+//
+// Path flags have no values so the shell completes file paths instead.
+func flagValues(flag cli.Flag, vaultPath string) []string {
+	switch flag.Names()[0] {
+	case "filter":
+		return cachedNames(vaultPath, entryNames)
+	case "group":
+		return cachedNames(vaultPath, func(entry avdu.EntryMetadata) []string {
+			return entry.Groups
+		})
+	case "sort":
+		return []string{string(vault.SortCustom), string(vault.SortIssuer), string(vault.SortName), string(vault.SortUsage)}
+	case "select":
+		return []string{string(avdu.SelectFilenameTime), string(avdu.SelectModTime), string(avdu.SelectVersion), string(avdu.SelectIndex)}
+	case "policy":
+		return []string{string(vault.PreferNewest), string(vault.PreferLeft), string(vault.PreferInteractive)}
+	case "launcher":
+		return []string{launcherRofi, launcherDmenu, launcherFzf}
+	case "format":
+		return slices.Sorted(maps.Keys(statusFormats))
+	case "write-index":
+		return []string{indexEncrypted, indexPlain}
+	}
+
+	return nil
+}
+
+// entryNames is a helper to get the names an entry can be found by.
+func entryNames(entry avdu.EntryMetadata) []string {
+	return []string{entry.Issuer, entry.Name}
+}
+
+// cachedNames is a helper to collect the unique, non-empty names
+// of the indexed or cached entries in the order they're first found.
+func cachedNames(vaultPath string, names func(entry avdu.EntryMetadata) []string) []string {
+	var entries []avdu.EntryMetadata
+
+	if index, err := avdu.ReadVaultIndex(vaultPath, nil); err == nil {
+		entries = index.Entries
+	} else if cache, err := readNameCache(); err == nil {
+		entries = cache.Entries
+	}
+
+	var values []string
+
+	for _, entry := range entries {
+		for _, name := range names(entry) {
+			if name != "" && !slices.Contains(values, name) {
+				values = append(values, name)
+			}
+		}
+	}
+
+	return values
+}
+
+// matchPrefix is a helper to keep the candidates that start with the prefix regardless of case.
+func matchPrefix(candidates []string, prefix string) []string {
+	var matches []string
+
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(prefix)) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	return matches
+}
+
+// unquoteWord is a helper to remove the shell quotes and backslash escapes from a word.
+// The word may end inside an unterminated quote while it's being typed.
+func unquoteWord(word string) string {
+	var builder strings.Builder
+	var quote rune
+	var escaped bool
+
+	for _, r := range word {
+		switch {
+		case escaped:
+			builder.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+		case r == quote:
+			quote = 0
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// findFlag is a helper to find the flag with the name or alias.
+func findFlag(flags []cli.Flag, name string) cli.Flag {
+	for _, flag := range flags {
+		if slices.Contains(flag.Names(), name) {
+			return flag
+		}
+	}
+
+	return nil
+}
+
+// findCommand is a helper to find the command with the name or alias.
+func findCommand(commands []*cli.Command, name string) *cli.Command {
+	for _, command := range commands {
+		if command.HasName(name) {
+			return command
+		}
+	}
+
+	return nil
+}
+
+// takesValue is a helper to check if the flag reads the following word as its value.
+func takesValue(flag cli.Flag) bool {
+	docFlag, ok := flag.(cli.DocGenerationFlag)
+
+	return ok && docFlag.TakesValue()
+}
+
+// nameCachePath is a helper to get the path of the name cache in the user's cache directory.
+func nameCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "avdu", nameCacheFile), nil
+}
+
+// readNameCache is a helper to read the cached entry names.
+func readNameCache() (nameCache, error) {
+	var cache nameCache
+
+	path, err := nameCachePath()
+	if err != nil {
+		return cache, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, err
+	}
+
+	err = json.Unmarshal(data, &cache)
+
+	return cache, err
+}
+
+// saveNameCache is a helper to cache the vault's entry names for completion
+// when requested by the flag.
+//
+// Failures are logged rather than returned since the cache is optional.
+func saveNameCache(ctx *cli.Context, source string, vaultData *vault.Vault) {
+	if !ctx.Bool("cache-names") {
+		return
+	}
+
+	if err := writeNameCache(source, vaultData); err != nil {
+		log.Printf("cannot cache entry names: %v", err)
+	}
+}
+
+// writeNameCache is a helper to write the vault's entry metadata to the name cache.
+func writeNameCache(source string, vaultData *vault.Vault) error {
+	path, err := nameCachePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	if absSource, err := filepath.Abs(source); err == nil && source != stdinPath {
+		source = absSource
+	}
+
+	data, err := json.Marshal(nameCache{Vault: source, Entries: avdu.Metadata(vaultData)})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+const (
+	indexEncrypted string = "encrypted" // Encrypt the index with a key derived from the master key
+	indexPlain     string = "plain"     // Store the index unencrypted so it's readable without the password
+)
+
+// saveIndex is a helper to write the vault file's index when requested by the flag.
+//
+// Failures are logged rather than returned since the index is optional.
+func saveIndex(ctx *cli.Context, vaultPath string, write func(vaultPath string, plaintext bool) error) {
+	var mode string = ctx.String("write-index")
+
+	if mode == "" {
+		return
+	}
+
+	if mode != indexEncrypted && mode != indexPlain {
+		log.Printf("cannot write index: unknown mode %q (want %v or %v)", mode, indexEncrypted, indexPlain)
+		return
+	}
+
+	if err := write(vaultPath, mode == indexPlain); err != nil {
+		log.Printf("cannot write index for %q: %v", vaultPath, err)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+)
+
+var vectorsComplete = []struct {
+	words []string
+	want  []string
+}{
+	{[]string{"li"}, []string{"list", "list-vaults"}},
+	{[]string{"icons", ""}, []string{"export", "apply"}},
+	{[]string{"usage", "i"}, []string{"import"}},
+	{[]string{"__comp"}, nil},
+	{[]string{"--so"}, []string{"--sort"}},
+	{[]string{"code", "--rec"}, []string{"--record-usage"}},
+	{[]string{"pick", "-"}, []string{"--path", "-p", "--select", "--index", "--launcher", "--print", "--selection", "--copy", "-c", "--password-file", "--record-usage"}},
+	{[]string{"--sort", ""}, []string{"custom", "issuer", "name", "usage"}},
+	{[]string{"--sort=", "--gr"}, []string{"--grouped", "--group"}},
+	{[]string{"-p", "vault.json", "--select", "m"}, []string{"mtime"}},
+	{[]string{"pick", "--launcher", "R"}, []string{"rofi"}},
+	{[]string{"statusbar", "--format", "t"}, []string{"tmux"}},
+	{[]string{"merge", "--policy", "n"}, []string{"newest"}},
+	{[]string{"completion", ""}, []string{"bash", "fish", "zsh"}},
+	{[]string{"-p", ""}, nil},
+}
+
+func TestCompleteWords(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	for i, vector := range vectorsComplete {
+		if got := completeWords(newApp(), vector.words); !slices.Equal(got, vector.want) {
+			t.Fatalf("[%v] completeWords(%q) = %q; want %q", i, vector.words, got, vector.want)
+		}
+	}
+}
+
+var vectorsCompleteNames = []struct {
+	words []string
+	want  []string
+}{
+	// Names from the name cache
+	{[]string{"code", "Air"}, []string{"Airbnb", "Air Canada"}},
+	{[]string{"show", "'air c"}, []string{"Air Canada"}},
+	{[]string{"show", `Air\ C`}, []string{"Air Canada"}},
+	{[]string{"--filter", "ma"}, []string{"Mason"}},
+	{[]string{"list", "--group", ""}, []string{"Group 1", "Group 2"}},
+
+	// Names from the vault's plaintext index, which is preferred to the cache
+	{[]string{"-p", "{index}", "show", "Bo"}, []string{"Boeing"}},
+	{[]string{"code", "-p", "{index}", "S"}, []string{"SPDX", "Sophia"}},
+	{[]string{"-p", "{index}", "--group", ""}, nil},
+}
+
+func TestCompleteNames(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	vaultData, err := avdu.ReadVaultFile("../../test/data/aegis_plain_grouped_v3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeNameCache("aegis_plain_grouped_v3.json", vaultData); err != nil {
+		t.Fatal(err)
+	}
+
+	var indexPath string = filepath.Join(t.TempDir(), "aegis_encrypted.json")
+
+	data, err := os.ReadFile("../../test/data/aegis_encrypted.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(indexPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := avdu.Open(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.Unlock("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.WriteIndex(indexPath, true); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, vector := range vectorsCompleteNames {
+		var words []string = slices.Clone(vector.words)
+
+		if j := slices.Index(words, "{index}"); j >= 0 {
+			words[j] = indexPath
+		}
+
+		if got := completeWords(newApp(), words); !slices.Equal(got, vector.want) {
+			t.Fatalf("[%v] completeWords(%q) = %q; want %q", i, vector.words, got, vector.want)
+		}
+	}
+}
+
+var vectorsUnquote = []struct {
+	word string
+	want string
+}{
+	{"Air", "Air"},
+	{`Air\ Canada`, "Air Canada"},
+	{`'Air Can`, "Air Can"},
+	{`"Air Canada"`, "Air Canada"},
+	{`'it\'`, `it\`},
+	{`"say \"hi\""`, `say "hi"`},
+}
+
+func TestUnquoteWord(t *testing.T) {
+	for i, vector := range vectorsUnquote {
+		if got := unquoteWord(vector.word); got != vector.want {
+			t.Fatalf("[%v] unquoteWord(%q) = %q; want %q", i, vector.word, got, vector.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+func diffAction(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("expected 2 vault paths, got %v", ctx.NArg())
+	}
+
+	var oldPath, newPath string = ctx.Args().Get(0), ctx.Args().Get(1)
+	var pwd string
+
+	oldVault, err := readAnyVault(oldPath, &pwd)
+	if err != nil {
+		return fmt.Errorf("cannot read vault %q: %w", oldPath, err)
+	}
+
+	newVault, err := readAnyVault(newPath, &pwd)
+	if err != nil {
+		return fmt.Errorf("cannot read vault %q: %w", newPath, err)
+	}
+
+	var diff vault.DiffResult = vault.Diff(oldVault, newVault)
+	var reveal bool = ctx.Bool("reveal")
+
+	if !reveal {
+		diff = diff.Redacted()
+	}
+
+	if ctx.Bool("json") {
+		output, err := json.MarshalIndent(diff, "", "    ")
+		if err != nil {
+			return fmt.Errorf("cannot marshal diff: %w", err)
+		}
+
+		fmt.Println(string(output))
+		return nil
+	}
+
+	writeDiff(os.Stdout, diff, oldVault, newVault)
+
+	return nil
+}
+
+// readAnyVault is a helper to read a plaintext or encrypted vault file.
+//
+// The password is prompted for the first encrypted vault and reused for
+// any later vaults. If a later vault rejects it, that vault's password is
+// prompted for and reused instead.
+func readAnyVault(path string, pwd *string) (*vault.Vault, error) {
+	vaultData, vaultDataEnc, err := readVaultInput(path)
+	if err != nil || vaultData != nil {
+		return vaultData, err
+	}
+
+	var reused bool = *pwd != ""
+
+	if !reused {
+		*pwd, err = readPassword()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	masterKey, err := vaultDataEnc.FindMasterKey(*pwd)
+	if err != nil && reused {
+		*pwd, err = promptPassword(fmt.Sprintf("Enter password for %v: ", path))
+		if err != nil {
+			return nil, err
+		}
+
+		masterKey, err = vaultDataEnc.FindMasterKey(*pwd)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return vaultDataEnc.DecryptVault(masterKey)
+}
+
+// writeDiff is a helper to output the diff as readable text.
+func writeDiff(w io.Writer, diff vault.DiffResult, oldVault *vault.Vault, newVault *vault.Vault) {
+	if diff.Empty() {
+		fmt.Fprintln(w, "No changes")
+		return
+	}
+
+	for _, entry := range diff.Added {
+		fmt.Fprintf(w, "+ %v (%v) [%v]\n", entry.Issuer, entry.Name, entry.Uuid)
+	}
+
+	for _, entry := range diff.Removed {
+		fmt.Fprintf(w, "- %v (%v) [%v]\n", entry.Issuer, entry.Name, entry.Uuid)
+	}
+
+	for _, change := range diff.Changed {
+		var details []string
+
+		for _, kind := range change.Kinds {
+			switch kind {
+			case vault.ChangeRenamed:
+				details = append(details, fmt.Sprintf("renamed from %v (%v)", change.Old.Issuer, change.Old.Name))
+			case vault.ChangeSecretRotated:
+				details = append(details, fmt.Sprintf("secret rotated %v -> %v", change.Old.Info.Secret, change.New.Info.Secret))
+			case vault.ChangeGroups:
+				var oldGroups string = strings.Join(oldVault.Db.EntryGroupNames(change.Old), ", ")
+				var newGroups string = strings.Join(newVault.Db.EntryGroupNames(change.New), ", ")
+
+				details = append(details, fmt.Sprintf("groups [%v] -> [%v]", oldGroups, newGroups))
+			case vault.ChangeOther:
+				details = append(details, "other fields changed")
+			}
+		}
+
+		fmt.Fprintf(w, "~ %v (%v) [%v]: %v\n", change.New.Issuer, change.New.Name, change.New.Uuid, strings.Join(details, ", "))
+	}
+
+	for _, group := range diff.AddedGroups {
+		fmt.Fprintf(w, "+ group %v [%v]\n", group.Name, group.Uuid)
+	}
+
+	for _, group := range diff.RemovedGroups {
+		fmt.Fprintf(w, "- group %v [%v]\n", group.Name, group.Uuid)
+	}
+
+	for _, change := range diff.RenamedGroups {
+		fmt.Fprintf(w, "~ group %v [%v]: renamed from %v\n", change.New.Name, change.New.Uuid, change.Old.Name)
+	}
+}
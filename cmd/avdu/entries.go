@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// vaultFlags are the flags the entry commands use to choose the vault.
+var vaultFlags []cli.Flag = []cli.Flag{
+	&cli.PathFlag{
+		Name:    "path",
+		Aliases: []string{"p"},
+		Usage:   "specify the path to the vault file or directory, or - to read from stdin",
+		Value:   ".",
+	},
+	selectFlag,
+	indexFlag,
+}
+
+var passwordFileFlag = &cli.PathFlag{
+	Name:  "password-file",
+	Usage: "read the vault password from the first line of the file instead of prompting",
+}
+
+func listAction(ctx *cli.Context) error {
+	db, err := readListDb(ctx)
+	if err != nil {
+		return err
+	}
+
+	view, err := newOTPView(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var vaultData *vault.Vault = view.filtered(&vault.Vault{Db: db})
+
+	vaultData.Db.Entries = vault.SortEntries(vaultData.Db.Entries, view.sort)
+
+	var entries []avdu.EntryMetadata = avdu.Metadata(vaultData)
+
+	if ctx.Bool("json") {
+		output, err := json.MarshalIndent(entries, "", "    ")
+		if err != nil {
+			return fmt.Errorf("cannot marshal entries: %w", err)
+		}
+
+		fmt.Println(string(output))
+
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "\tISSUER\tNAME\tTYPE\tALGO\tDIGITS\tPERIOD\tGROUPS\tUUID")
+
+	for _, entry := range entries {
+		var favorite string
+
+		if entry.Favorite {
+			favorite = "*"
+		}
+
+		var period string = "-"
+
+		if entry.Period > 0 {
+			period = fmt.Sprintf("%vs", entry.Period)
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", favorite, entry.Issuer, entry.Name,
+			entry.Type, entry.Algo, entry.Digits, period, strings.Join(entry.Groups, ", "), entry.Uuid)
+	}
+
+	return writer.Flush()
+}
+
+func showAction(ctx *cli.Context) error {
+	vaultData, entry, err := unlockEntry(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !ctx.Bool("reveal") {
+		entry = entry.Redacted()
+	}
+
+	var icon string = "none"
+
+	if entry.Icon != "" {
+		icon = entry.IconMime
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "Issuer:\t%v\n", entry.Issuer)
+	fmt.Fprintf(writer, "Name:\t%v\n", entry.Name)
+	fmt.Fprintf(writer, "Uuid:\t%v\n", entry.Uuid)
+	fmt.Fprintf(writer, "Type:\t%v\n", entry.Type)
+	fmt.Fprintf(writer, "Algo:\t%v\n", entry.Info.Algo)
+	fmt.Fprintf(writer, "Digits:\t%v\n", entry.Info.Digits)
+
+	if entry.Type == "hotp" {
+		fmt.Fprintf(writer, "Counter:\t%v\n", entry.Info.Counter)
+	} else {
+		fmt.Fprintf(writer, "Period:\t%vs\n", entry.Info.Period)
+	}
+
+	fmt.Fprintf(writer, "Groups:\t%v\n", strings.Join(vaultData.Db.EntryGroupNames(entry), ", "))
+	fmt.Fprintf(writer, "Favorite:\t%v\n", entry.Favorite)
+	fmt.Fprintf(writer, "Note:\t%v\n", entry.Note)
+	fmt.Fprintf(writer, "Icon:\t%v\n", icon)
+	fmt.Fprintf(writer, "Secret:\t%v\n", entry.Info.Secret)
+
+	if entry.Info.Pin != "" {
+		fmt.Fprintf(writer, "Pin:\t%v\n", entry.Info.Pin)
+	}
+
+	return writer.Flush()
+}
+
+func codeAction(ctx *cli.Context) error {
+	_, entry, err := unlockEntry(ctx)
+	if err != nil {
+		return err
+	}
+
+	pass, err := codeFor(entry)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(pass)
+
+	recordUsage(ctx, entry)
+
+	return nil
+}
+
+// readListDb is a helper to read the database to list, using the encrypted
+// vault's plaintext index when it's current so no password is needed.
+func readListDb(ctx *cli.Context) (vault.Db, error) {
+	vaultPath, err := selectVaultPath(ctx)
+	if err != nil {
+		return vault.Db{}, err
+	}
+
+	if vaultPath != stdinPath && !ctx.Bool("no-index") {
+		if index, err := avdu.ReadVaultIndex(vaultPath, nil); err == nil {
+			return index.Db(), nil
+		}
+	}
+
+	vaultData, err := unlockPath(ctx, vaultPath)
+	if err != nil {
+		return vault.Db{}, err
+	}
+
+	return vaultData.Db, nil
+}
+
+// unlockEntry is a helper to unlock the selected vault
+// and find the entry identified by the first argument.
+func unlockEntry(ctx *cli.Context) (*vault.Vault, vault.Entry, error) {
+	var query string = ctx.Args().First()
+
+	if query == "" {
+		return nil, vault.Entry{}, errors.New("an entry uuid, issuer, or name is required")
+	}
+
+	vaultPath, err := selectVaultPath(ctx)
+	if err != nil {
+		return nil, vault.Entry{}, err
+	}
+
+	vaultData, err := unlockPath(ctx, vaultPath)
+	if err != nil {
+		return nil, vault.Entry{}, err
+	}
+
+	entry, err := vaultData.Db.FindEntry(query)
+	if err != nil {
+		return nil, vault.Entry{}, err
+	}
+
+	return vaultData, entry, nil
+}
+
+// selectVaultPath is a helper to find the vault file for the path flag,
+// choosing one of the vaults with the select flags when the path is a directory.
+func selectVaultPath(ctx *cli.Context) (string, error) {
+	var path string = ctx.Path("path")
+
+	if path == stdinPath {
+		return path, nil
+	}
+
+	var isFilePath bool = strings.EqualFold(filepath.Ext(path), ".json")
+
+	if isFilePath {
+		return path, nil
+	}
+
+	policy, err := avdu.ParseSelectPolicy(ctx.String("select"))
+	if err != nil {
+		return "", err
+	}
+
+	return avdu.FindVaultPathWith(path, policy, ctx.Int("index"))
+}
+
+// unlockPath is a helper to open the vault file at the path, or stdin,
+// and prompt for the password if it's encrypted.
+func unlockPath(ctx *cli.Context, vaultPath string) (*vault.Vault, error) {
+	var file *avdu.VaultFile
+	var err error
+
+	if vaultPath == stdinPath {
+		file, err = avdu.OpenReader(os.Stdin)
+	} else {
+		file, err = avdu.Open(vaultPath)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read vault %q: %w", vaultPath, err)
+	}
+
+	var pwd string
+
+	if file.Encrypted() {
+		pwd, err = readPasswordFlag(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vaultData, err := unlockVault(ctx.Context, file, pwd)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt vault %q: %w", vaultPath, err)
+	}
+
+	return vaultData, nil
+}
+
+// readPasswordFlag is a helper to read the vault password from the first line
+// of the password file flag, or prompt for it when the flag isn't set.
+func readPasswordFlag(ctx *cli.Context) (string, error) {
+	var path string = ctx.Path("password-file")
+
+	if path == "" {
+		return readPassword()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read password file %q: %w", path, err)
+	}
+
+	pwd, _, _ := strings.Cut(string(data), "\n")
+
+	return strings.TrimSuffix(pwd, "\r"), nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestSelectVaultPath(t *testing.T) {
+	// A directory whose name contains "json" is still searched for vault files
+	var dir string = filepath.Join(t.TempDir(), "myjsonbackups")
+	var backupPath string = filepath.Join(dir, "aegis-backup-20240625-000001.json")
+
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(backupPath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var vectors = []struct {
+		path string
+		want string
+	}{
+		{dir, backupPath},
+		{"vault.json", "vault.json"},
+		{"VAULT.JSON", "VAULT.JSON"},
+		{stdinPath, stdinPath},
+	}
+
+	for i, vector := range vectors {
+		var set *flag.FlagSet = flag.NewFlagSet("list", flag.ContinueOnError)
+
+		set.String("path", vector.path, "")
+		set.String("select", "filename", "")
+		set.Int("index", 0, "")
+
+		if got, err := selectVaultPath(cli.NewContext(cli.NewApp(), set, nil)); err != nil || got != vector.want {
+			t.Fatalf("[%v] selectVaultPath(%q) = %q, %v; want %q, nil", i, vector.path, got, err, vector.want)
+		}
+	}
+}
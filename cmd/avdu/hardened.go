@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// hardenedAction is a helper to display the OTPs of an encrypted vault
+// using a hardened vault so secrets are only decrypted while generating codes.
+//
+// Directories aren't watched in hardened mode since reloading
+// requires keeping the password in memory.
+func hardenedAction(ctx *cli.Context, file *avdu.VaultFile, source string) error {
+	pwd, err := promptPasswordBytes("Enter password: ")
+	if err != nil {
+		return err
+	}
+
+	hardened, err := file.UnlockHardened(pwd)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt vault %q: %w", source, err)
+	}
+
+	defer hardened.Close()
+
+	if !hardened.MemoryLocked() {
+		log.Println("warning: the master key's memory couldn't be locked and may be swapped to disk")
+	}
+
+	var generate otpGenerator = func(vaultData *vault.Vault) map[string]avdu.OTPResult {
+		// Failed entries are shown inline so the error isn't checked separately
+		results, _ := hardened.GetOTPResults()
+
+		return results
+	}
+
+	view, err := newOTPView(ctx, generate)
+	if err != nil {
+		return err
+	}
+
+	saveNameCache(ctx, source, hardened.Vault())
+
+	if source != stdinPath {
+		saveIndex(ctx, source, hardened.WriteIndex)
+	}
+
+	fmt.Printf("%v Read file: %v\n", time.Now().Format(timeFmt), source)
+
+	if !ctx.Bool("refresh") {
+		displayOTPs(hardened.Vault(), view)
+		fmt.Printf("OTPs valid for %vs\n", float32(avdu.GetTTN())/1000)
+
+		return nil
+	}
+
+	var ch chan int = make(chan int)
+
+	go countdownOTPs(hardened.Vault(), view, nil, ch)
+
+	<-ch
+
+	return nil
+}
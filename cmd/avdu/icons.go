@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/sammy-t/avdu/iconpack"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// uuidRE matches a plain uuid, which is safe to use as a file name.
+var uuidRE = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func iconsExportAction(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected an output directory, got %v args", ctx.NArg())
+	}
+
+	var path string = ctx.Path("path")
+	var outputDir string = ctx.Args().First()
+	var pwd string
+
+	vaultData, err := readAnyVault(path, &pwd)
+	if err != nil {
+		return fmt.Errorf("cannot read vault %q: %w", path, err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("cannot create %q: %w", outputDir, err)
+	}
+
+	var exported int
+
+	for _, entry := range vaultData.Db.Entries {
+		// The uuid names the icon file, so one like "../x" could write outside the directory
+		if !uuidRE.MatchString(entry.Uuid) {
+			log.Printf("skipping icon of %v (%v) [%q]: not a valid uuid", entry.Issuer, entry.Name, entry.Uuid)
+			continue
+		}
+
+		data, err := entry.DecodeIcon()
+		if errors.Is(err, vault.ErrNoIcon) {
+			continue
+		} else if err != nil {
+			log.Printf("skipping icon of %v (%v) [%v]: %v", entry.Issuer, entry.Name, entry.Uuid, err)
+			continue
+		}
+
+		ext, _ := vault.IconExtension(entry.IconMime) // The mime type was checked when decoding
+
+		var iconPath string = filepath.Join(outputDir, entry.Uuid+ext)
+
+		if err := os.WriteFile(iconPath, data, 0644); err != nil {
+			return fmt.Errorf("cannot write to %q: %w", iconPath, err)
+		}
+
+		exported++
+	}
+
+	fmt.Fprintf(os.Stderr, "%v icons exported to %v\n", exported, outputDir)
+
+	return nil
+}
+
+func iconsApplyAction(ctx *cli.Context) error {
+	var path string = ctx.Path("path")
+	var packPath string = ctx.Path("pack")
+
+	pack, err := iconpack.Open(packPath)
+	if err != nil {
+		return err
+	}
+
+	defer pack.Close()
+
+	return rewriteVault(path, ctx.Path("output"), func(vaultData *vault.Vault) error {
+		updated, err := pack.Apply(&vaultData.Db, ctx.Bool("overwrite"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "%v icons assigned from %v\n", len(updated), pack.Name)
+
+		return nil
+	})
+}
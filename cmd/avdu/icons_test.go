@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+func TestIconsExportAction(t *testing.T) {
+	var dir string = t.TempDir()
+	var outputDir string = filepath.Join(dir, "icons", "out")
+
+	var entries []vault.Entry = []vault.Entry{
+		{Type: "totp", Uuid: "3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d", Issuer: "Deno", Name: "Mason"},
+		{Type: "totp", Uuid: "../escaped", Issuer: "Airbnb", Name: "Elijah"},
+		{Type: "totp", Uuid: "nested/icon", Issuer: "Issuu", Name: "James"},
+	}
+
+	for i := range entries {
+		if err := entries[i].SetIcon([]byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), "image/svg+xml"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := json.Marshal(vault.Vault{Version: 1, Db: vault.Db{Version: vault.LatestDbVersion, Entries: entries}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vaultPath string = filepath.Join(dir, "vault.json")
+
+	if err := os.WriteFile(vaultPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var set *flag.FlagSet = flag.NewFlagSet("export", flag.ContinueOnError)
+
+	set.String("path", vaultPath, "")
+
+	if err := set.Parse([]string{outputDir}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := iconsExportAction(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the entry with a plain uuid is exported, and nothing is written outside the directory
+	var want []string = []string{filepath.Join(outputDir, entries[0].Uuid+".svg")}
+
+	var got []string
+
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && path != vaultPath {
+			got = append(got, path)
+		}
+
+		return err
+	})
+
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("iconsExportAction() wrote %v, %v; want %v", got, err, want)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+)
+
+// stdinPath is the path that reads the vault from stdin instead of a file.
+const stdinPath string = "-"
+
+// readVaultInput is a helper to read a plaintext or encrypted vault
+// from the file at the path, or from stdin when the path is "-".
+//
+// Exactly one of the returned vaults is non-nil when there's no error.
+func readVaultInput(path string) (*vault.Vault, *vault.VaultEncrypted, error) {
+	if path == stdinPath {
+		return avdu.ReadAnyVault(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer file.Close()
+
+	return avdu.ReadAnyVault(file)
+}
+
+// decryptInput is a helper to prompt for the password
+// and decrypt the vault, returning the master key as well.
+func decryptInput(vaultDataEnc *vault.VaultEncrypted) (*vault.Vault, []byte, error) {
+	pwd, err := readPassword()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	masterKey, err := vaultDataEnc.FindMasterKey(pwd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vaultData, err := vaultDataEnc.DecryptVault(masterKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vaultData, masterKey, nil
+}
+
+// rewriteVault is a helper to read the vault, modify it, and write it to the output path
+// or stdout. Encrypted vaults are re-encrypted with the same master key
+// so the existing slots still unlock the vault.
+func rewriteVault(path string, outputPath string, modify func(vaultData *vault.Vault) error) error {
+	vaultData, vaultDataEnc, err := readVaultInput(path)
+	if err != nil {
+		return fmt.Errorf("cannot read vault %q: %w", path, err)
+	}
+
+	if vaultDataEnc == nil {
+		if err := modify(vaultData); err != nil {
+			return err
+		}
+
+		return writeVaultOutput(vaultData, outputPath)
+	}
+
+	vaultData, masterKey, err := decryptInput(vaultDataEnc)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt vault %q: %w", path, err)
+	}
+
+	defer vault.Wipe(masterKey)
+
+	if err := modify(vaultData); err != nil {
+		return err
+	}
+
+	modified, err := vaultData.Encrypt(masterKey, vaultDataEnc.Header.Slots)
+	if err != nil {
+		return err
+	}
+
+	return writeVaultOutput(modified, outputPath)
+}
@@ -2,10 +2,12 @@
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
+	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -22,7 +24,14 @@
 var refreshes int
 
 func main() {
-	app := &cli.App{
+	if err := newApp().Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newApp is a helper to create the command line app with its flags and commands.
+func newApp() *cli.App {
+	return &cli.App{
 		Name:    "avdu",
 		Usage:   "Generate one-time passwords from an Aegis Authenticator vault backup or export file.",
 		Version: "0.5.0",
@@ -30,22 +39,371 @@ func main() {
 			&cli.PathFlag{
 				Name:    "path",
 				Aliases: []string{"p"},
-				Usage:   "specify the path to the vault file or directory",
+				Usage:   "specify the path to the vault file or directory, or - to read from stdin",
 				Value:   ".",
 			},
 			&cli.BoolFlag{
 				Name:    "encrypted",
 				Aliases: []string{"enc", "e"},
-				Usage:   "enables password input for decrypting the vault",
+				Usage:   "prompt for the password even if the vault is plaintext (encrypted vaults are detected automatically)",
 			},
 			&cli.BoolFlag{
 				Name:    "refresh",
 				Aliases: []string{"r"},
 				Usage:   "automatically refreshes the OTP display [experimental]",
 			},
+			&cli.BoolFlag{
+				Name:  "hardened",
+				Usage: "keep the master key in locked memory and only decrypt secrets while generating codes (disables reloading)",
+			},
+			selectFlag,
+			indexFlag,
+			sortFlag,
+			favoritesFirstFlag,
+			groupedFlag,
+			filterFlag,
+			groupFlag,
+			cacheNamesFlag,
+			writeIndexFlag,
 		},
 		Action: cliAction,
 		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the vault's entries without generating codes",
+				Flags: append(slices.Clone(vaultFlags),
+					filterFlag,
+					groupFlag,
+					sortFlag,
+					favoritesFirstFlag,
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "output the entries as json",
+					},
+					&cli.BoolFlag{
+						Name:  "no-index",
+						Usage: "decrypt the vault even if it has a current plaintext index",
+					},
+				),
+				Action: listAction,
+			},
+			{
+				Name:      "show",
+				Usage:     "Show the details of an entry",
+				ArgsUsage: "<uuid, issuer, or name>",
+				Flags: append(slices.Clone(vaultFlags),
+					&cli.BoolFlag{
+						Name:  "reveal",
+						Usage: "include the secret and pin in the output",
+					},
+				),
+				Action: showAction,
+			},
+			{
+				Name:      "code",
+				Usage:     "Print only the current code of an entry",
+				ArgsUsage: "<uuid, issuer, or name>",
+				Flags:     append(slices.Clone(vaultFlags), recordUsageFlag),
+				Action:    codeAction,
+			},
+			{
+				Name:      "statusbar",
+				Usage:     "Continuously print entry codes for a status bar, updating at each period boundary",
+				ArgsUsage: "[uuid, issuer, or name...]",
+				Flags: append(slices.Clone(vaultFlags),
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "the status bar's output format (waybar, i3blocks, polybar, tmux)",
+						Value: "waybar",
+					},
+					&cli.BoolFlag{
+						Name:  "once",
+						Usage: "print a single line and exit",
+					},
+					passwordFileFlag,
+				),
+				Action: statusbarAction,
+			},
+			{
+				Name:  "pick",
+				Usage: "Choose an entry with rofi, dmenu, or fzf and print or copy its code",
+				Flags: append(slices.Clone(vaultFlags),
+					&cli.StringFlag{
+						Name:  "launcher",
+						Usage: "the launcher to choose with (rofi, dmenu, fzf); rofi runs avdu as a script mode",
+						Value: launcherFzf,
+					},
+					&cli.BoolFlag{
+						Name:  "print",
+						Usage: "only print the launcher's input lines, which include each entry's uuid",
+					},
+					&cli.StringFlag{
+						Name:  "selection",
+						Usage: "the line or uuid selected in the launcher, skipping running it",
+					},
+					&cli.BoolFlag{
+						Name:    "copy",
+						Aliases: []string{"c"},
+						Usage:   "copy the code to the clipboard instead of printing it",
+					},
+					passwordFileFlag,
+					recordUsageFlag,
+				),
+				Action: pickAction,
+			},
+			{
+				Name:  "recent",
+				Usage: "List the vault's entries from most to least recently used, from the usage log",
+				Flags: append(slices.Clone(vaultFlags),
+					&cli.IntFlag{
+						Name:    "limit",
+						Aliases: []string{"n"},
+						Usage:   "the number of entries to list, or 0 for all",
+						Value:   10,
+					},
+				),
+				Action: recentAction,
+			},
+			{
+				Name:  "usage",
+				Usage: "Manage the local usage log that --record-usage writes and usage sorting reads",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "import",
+						Usage:     "Import the usage counts from Aegis' preferences file, replacing those imported before",
+						ArgsUsage: "<de.beemdevelopment.aegis_preferences.xml>",
+						Action:    usageImportAction,
+					},
+				},
+			},
+			{
+				Name:  "native-host",
+				Usage: "Serve a browser extension's native messaging requests for codes on matching sites",
+				Flags: append(slices.Clone(vaultFlags),
+					&cli.PathFlag{
+						Name:  "rules",
+						Usage: "path to the json file assigning sites to entries (defaults to avdu/rules.json in the user config directory)",
+					},
+				),
+				Action: nativeHostAction,
+			},
+			{
+				Name:  "list-vaults",
+				Usage: "List the vault files found in a directory and show which one is selected",
+				Flags: []cli.Flag{
+					&cli.PathFlag{
+						Name:    "path",
+						Aliases: []string{"p"},
+						Usage:   "path to the vault directory",
+						Value:   ".",
+					},
+					selectFlag,
+					indexFlag,
+				},
+				Action: listVaultsAction,
+			},
+			{
+				Name:  "prune",
+				Usage: "Remove old vault backups from a directory using a retention policy",
+				Flags: []cli.Flag{
+					&cli.PathFlag{
+						Name:    "path",
+						Aliases: []string{"p"},
+						Usage:   "path to the vault directory",
+						Value:   ".",
+					},
+					&cli.IntFlag{
+						Name:  "keep-last",
+						Usage: "the number of most recent vaults to keep",
+						Value: 10,
+					},
+					&cli.IntFlag{
+						Name:  "keep-daily",
+						Usage: "the number of days to keep the most recent vault for",
+					},
+					&cli.IntFlag{
+						Name:  "keep-weekly",
+						Usage: "the number of weeks to keep the most recent vault for",
+					},
+					&cli.IntFlag{
+						Name:  "keep-monthly",
+						Usage: "the number of months to keep the most recent vault for",
+					},
+					&cli.BoolFlag{
+						Name:  "dedupe",
+						Usage: "remove vaults with the same content as a more recent vault",
+					},
+					&cli.BoolFlag{
+						Name:    "encrypted",
+						Aliases: []string{"enc", "e"},
+						Usage:   "enables password input to check and deduplicate encrypted vaults",
+					},
+					&cli.BoolFlag{
+						Name:  "allow-unparsed",
+						Usage: "allow removing vaults whose file name or content can't be parsed",
+					},
+					&cli.BoolFlag{
+						Name:    "dry-run",
+						Aliases: []string{"n"},
+						Usage:   "show which vaults would be removed without removing them",
+					},
+				},
+				Action: pruneAction,
+			},
+			{
+				Name:      "diff",
+				Usage:     "Show the changes between two vault files",
+				ArgsUsage: "<old vault> <new vault>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "output the changes as json",
+					},
+					&cli.BoolFlag{
+						Name:  "reveal",
+						Usage: "include secrets and pins in the output",
+					},
+				},
+				Action: diffAction,
+			},
+			{
+				Name:      "merge",
+				Usage:     "Merge the entries and groups of multiple vault files",
+				ArgsUsage: "<vault> <vault> [vault...]",
+				Flags: []cli.Flag{
+					&cli.PathFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "output path for the merged vault (defaults to stdout)",
+					},
+					&cli.StringFlag{
+						Name:  "policy",
+						Usage: "which entry to keep on conflicts (newest, left, interactive)",
+						Value: string(vault.PreferNewest),
+					},
+					&cli.BoolFlag{
+						Name:  "encrypt",
+						Usage: "encrypt the merged vault with a new password",
+					},
+				},
+				Action: mergeAction,
+			},
+			{
+				Name:  "migrate",
+				Usage: "Convert a vault file's database to another version",
+				Flags: []cli.Flag{
+					&cli.PathFlag{
+						Name:     "path",
+						Aliases:  []string{"p"},
+						Usage:    "path to the vault file",
+						Required: true,
+					},
+					&cli.PathFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "output path for the migrated vault (defaults to stdout)",
+					},
+					dbVersionFlag,
+				},
+				Action: migrateAction,
+			},
+			{
+				Name:  "check",
+				Usage: "Validate a vault file and list every problem found",
+				Flags: []cli.Flag{
+					&cli.PathFlag{
+						Name:     "path",
+						Aliases:  []string{"p"},
+						Usage:    "path to the vault file",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "output the problems as json",
+					},
+				},
+				Action: checkAction,
+			},
+			{
+				Name:  "audit",
+				Usage: "Report weak secrets, algorithms, and password slots with a score out of 100",
+				Flags: []cli.Flag{
+					&cli.PathFlag{
+						Name:     "path",
+						Aliases:  []string{"p"},
+						Usage:    "path to the vault file",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "output the report as json",
+					},
+					&cli.IntFlag{
+						Name:  "min-score",
+						Usage: "exit with an error when the score is below the minimum",
+					},
+				},
+				Action: auditAction,
+			},
+			{
+				Name:  "icons",
+				Usage: "Export entry icons or assign icons from an Aegis icon pack",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "export",
+						Usage:     "Write each entry's icon to a file named by the entry's uuid",
+						ArgsUsage: "<dir>",
+						Flags: []cli.Flag{
+							&cli.PathFlag{
+								Name:     "path",
+								Aliases:  []string{"p"},
+								Usage:    "path to the vault file",
+								Required: true,
+							},
+						},
+						Action: iconsExportAction,
+					},
+					{
+						Name:  "apply",
+						Usage: "Assign icons from an icon pack to entries with a matching issuer",
+						Flags: []cli.Flag{
+							&cli.PathFlag{
+								Name:     "path",
+								Aliases:  []string{"p"},
+								Usage:    "path to the vault file",
+								Required: true,
+							},
+							&cli.PathFlag{
+								Name:     "pack",
+								Usage:    "path to the icon pack zip file",
+								Required: true,
+							},
+							&cli.PathFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output path for the updated vault (defaults to stdout)",
+							},
+							&cli.BoolFlag{
+								Name:  "overwrite",
+								Usage: "replace the icons of entries that already have one",
+							},
+						},
+						Action: iconsApplyAction,
+					},
+				},
+			},
+			{
+				Name:      "completion",
+				Usage:     "Output a shell completion script that also completes entry names and groups",
+				ArgsUsage: "<bash|zsh|fish>",
+				Action:    completionAction,
+			},
+			{
+				Name:            completeCommand,
+				Hidden:          true,
+				SkipFlagParsing: true,
+				Action:          completeAction,
+			},
 			{
 				Name:  "decrypt",
 				Usage: "Decrypt an encrypted vault file to plaintext format",
@@ -66,72 +424,101 @@ func main() {
 			},
 		},
 	}
-
-	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
-	}
 }
 
 func cliAction(ctx *cli.Context) error {
 	var path string = ctx.Path("path")
 
-	isFilePath, err := regexp.MatchString(`.json`, path)
-	if err != nil {
-		return err
+	if path == stdinPath {
+		return stdinAction(ctx)
 	}
 
+	var isFilePath bool = strings.EqualFold(filepath.Ext(path), ".json")
+
 	var vaultPath string
 
+	policy, err := avdu.ParseSelectPolicy(ctx.String("select"))
+	if err != nil {
+		return err
+	}
+
 	if isFilePath {
 		vaultPath = path
 	} else {
-		vaultPath, err = avdu.FindVaultPath(path)
+		vaultPath, err = avdu.FindVaultPathWith(path, policy, ctx.Int("index"))
 	}
 
 	if err != nil {
 		return err
 	}
 
-	var encrypted bool = ctx.Bool("encrypted")
-	var pwd string
+	file, err := avdu.Open(vaultPath)
+	if err != nil {
+		return fmt.Errorf("cannot read vault %q: %w", vaultPath, err)
+	}
 
-	if encrypted {
-		fmt.Print("Enter password: ")
+	if ctx.Bool("hardened") {
+		if file.Encrypted() {
+			return hardenedAction(ctx, file, vaultPath)
+		}
 
-		pwdBytes, err := term.ReadPassword(int(syscall.Stdin))
-		pwd = string(pwdBytes)
+		log.Println("warning: --hardened has no effect on a plaintext vault, whose secrets are already unencrypted")
+	}
 
-		fmt.Println() // Ensure there's a newline for the next output
+	var pwd string
 
+	// Only prompt when needed unless the password was requested up front
+	if file.Encrypted() || ctx.Bool("encrypted") {
+		pwd, err = readPassword()
 		if err != nil {
-			return fmt.Errorf("cannot read password input %q: %w", pwd, err)
+			return err
 		}
 	}
 
+	var refresh bool = ctx.Bool("refresh")
+
 	var vaultData *vault.Vault
+	var watcher *avdu.Watcher
 
-	if pwd == "" {
-		vaultData, err = avdu.ReadVaultFile(vaultPath)
+	if refresh && !isFilePath {
+		// Watch the directory so new backups are picked up while refreshing
+		watcher = avdu.NewWatcher(path, pwd, 0)
+		watcher.SetSelection(policy, ctx.Int("index"))
+		vaultPath, vaultData, err = watcher.Load()
 	} else {
-		vaultData, err = avdu.ReadAndDecryptVaultFile(vaultPath, pwd)
+		vaultData, err = unlockVault(ctx.Context, file, pwd)
 	}
 
 	if err != nil {
 		return fmt.Errorf("cannot read vault %q: %w", vaultPath, err)
 	}
 
-	fmt.Printf("%v Read file: %v\n", time.Now().Format(timeFmt), vaultPath)
+	if watcher == nil && file.Encrypted() {
+		saveIndex(ctx, vaultPath, file.WriteIndex)
+	}
 
-	displayOTPs(vaultData)
+	view, err := newOTPView(ctx, plainOTPs)
+	if err != nil {
+		return err
+	}
 
-	var refresh bool = ctx.Bool("refresh")
+	saveNameCache(ctx, vaultPath, vaultData)
+
+	fmt.Printf("%v Read file: %v\n", time.Now().Format(timeFmt), vaultPath)
+
+	displayOTPs(vaultData, view)
 
 	if !refresh {
 		fmt.Printf("OTPs valid for %vs\n", float32(avdu.GetTTN())/1000)
 	} else {
 		var ch chan int = make(chan int)
 
-		go countdownOTPs(vaultData, ch)
+		if watcher != nil {
+			watcher.Start()
+			defer watcher.Stop()
+		}
+
+		go countdownOTPs(vaultData, view, watcher, ch)
 
 		// Block progression by waiting to receive data on the channel.
 		// (This isn't necessary if I remove the goroutine but I'll keep it.)
@@ -141,36 +528,157 @@ func cliAction(ctx *cli.Context) error {
 	return nil
 }
 
-// displayOTPs is a helper to output the OTP data.
-func displayOTPs(vaultData *vault.Vault) {
-	otps, err := avdu.GetOTPs(vaultData)
+// stdinAction is a helper to display the OTPs of a vault read from stdin.
+func stdinAction(ctx *cli.Context) error {
+	file, err := avdu.OpenReader(os.Stdin)
 	if err != nil {
-		log.Println(err)
+		return fmt.Errorf("cannot read vault from stdin: %w", err)
 	}
 
+	if ctx.Bool("hardened") {
+		if file.Encrypted() {
+			return hardenedAction(ctx, file, stdinPath)
+		}
+
+		log.Println("warning: --hardened has no effect on a plaintext vault, whose secrets are already unencrypted")
+	}
+
+	var pwd string
+
+	if file.Encrypted() {
+		pwd, err = readPassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	vaultData, err := unlockVault(ctx.Context, file, pwd)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt vault from stdin: %w", err)
+	}
+
+	view, err := newOTPView(ctx, plainOTPs)
+	if err != nil {
+		return err
+	}
+
+	saveNameCache(ctx, stdinPath, vaultData)
+
+	fmt.Printf("%v Read stdin\n", time.Now().Format(timeFmt))
+
+	if !ctx.Bool("refresh") {
+		displayOTPs(vaultData, view)
+		fmt.Printf("OTPs valid for %vs\n", float32(avdu.GetTTN())/1000)
+
+		return nil
+	}
+
+	var ch chan int = make(chan int)
+
+	// Stdin can't be watched for changes
+	go countdownOTPs(vaultData, view, nil, ch)
+
+	<-ch
+
+	return nil
+}
+
+// displayOTPs is a helper to output the OTP data.
+func displayOTPs(vaultData *vault.Vault, view otpView) {
+	vaultData = view.filtered(vaultData)
+
+	var results map[string]avdu.OTPResult = view.generate(vaultData)
+
 	var builder strings.Builder
 
 	builder.WriteString("- OTPs -\n")
 
-	for _, entry := range vaultData.Db.Entries {
-		fmt.Fprintf(&builder, "%v (%v): %v\n", entry.Issuer, entry.Name, otps[entry.Uuid])
+	if !view.grouped {
+		for _, entry := range vault.SortEntries(vaultData.Db.Entries, view.sort) {
+			writeOTP(&builder, "", entry, results[entry.Uuid])
+		}
+	} else {
+		for _, group := range vaultData.Db.GroupedEntries(view.sort) {
+			var heading string = group.Group.Name
+
+			if heading == "" {
+				heading = "No group"
+			}
+
+			fmt.Fprintf(&builder, "[%v]\n", heading)
+
+			for _, entry := range group.Entries {
+				writeOTP(&builder, "  ", entry, results[entry.Uuid])
+			}
+		}
 	}
 
 	fmt.Printf("%v\n%v\n", time.Now().Format(timeFmt), builder.String())
 }
 
+// writeOTP is a helper to output an entry's OTP or the reason it failed.
+func writeOTP(builder *strings.Builder, indent string, entry vault.Entry, result avdu.OTPResult) {
+	var favorite string
+
+	if entry.Favorite {
+		favorite = "* "
+	}
+
+	if result.Err != nil {
+		fmt.Fprintf(builder, "%v%v%v (%v): error: %v\n", indent, favorite, entry.Issuer, entry.Name, errors.Unwrap(result.Err))
+		return
+	}
+
+	fmt.Fprintf(builder, "%v%v%v (%v): %v\n", indent, favorite, entry.Issuer, entry.Name, result.OTP)
+}
+
 // countdownOTPs outputs a countdown and displays the current OTPs
-// after each countdown reset.
-func countdownOTPs(vaultData *vault.Vault, ch chan int) {
-	displayOTPs(vaultData)
+// after each countdown reset or vault reload.
+//
+// A nil watcher disables reloading.
+func countdownOTPs(vaultData *vault.Vault, view otpView, watcher *avdu.Watcher, ch chan int) {
+	var events <-chan avdu.WatchEvent
+
+	if watcher != nil {
+		events = watcher.Events()
+	}
+
+	displayOTPs(vaultData, view)
 
 	for refreshes < refreshLimit {
+		select {
+		case event := <-events:
+			fmt.Println() // Ensure there's a fresh line
+
+			// Backups can become encrypted after a plaintext vault was first read
+			if errors.Is(event.Err, avdu.ErrPasswordRequired) {
+				fmt.Printf("%v Encrypted file: %v\n", time.Now().Format(timeFmt), event.Path)
+				unlockWatcher(watcher)
+				break
+			}
+
+			if event.Err != nil {
+				log.Printf("cannot reload vault %q: %v", event.Path, event.Err)
+				break
+			}
+
+			vaultData = event.Vault
+
+			var diff vault.DiffResult = event.Diff
+
+			fmt.Printf("%v Reloaded file: %v (+%v -%v ~%v)\n", time.Now().Format(timeFmt), event.Path,
+				len(diff.Added), len(diff.Removed), len(diff.Changed))
+
+			displayOTPs(vaultData, view)
+		default:
+		}
+
 		ttn := avdu.GetTTN()
 
 		if ttn > 29000 {
 			fmt.Println() // Ensure there's a fresh line
 
-			displayOTPs(vaultData)
+			displayOTPs(vaultData, view)
 
 			refreshes++
 		}
@@ -184,21 +692,39 @@ func countdownOTPs(vaultData *vault.Vault, ch chan int) {
 	ch <- 0 // Return arbitrary data to free up the channel
 }
 
+// unlockWatcher is a helper to prompt for the password of the watched
+// directory's encrypted vault files until one decrypts the current file.
+func unlockWatcher(watcher *avdu.Watcher) {
+	for {
+		pwd, err := readPassword()
+		if err != nil {
+			log.Printf("cannot read password: %v", err)
+			return
+		}
+
+		if err := watcher.SetPassword(pwd); err != nil {
+			log.Printf("cannot decrypt vault: %v", err)
+			continue
+		}
+
+		return
+	}
+}
+
 func decryptAction(ctx *cli.Context) error {
 	path := ctx.Path("path")
 	outputPath := ctx.Path("output")
 
-	fmt.Fprint(os.Stderr, "Enter password: ")
-
-	pwdBytes, err := term.ReadPassword(int(syscall.Stdin))
+	_, vaultDataEnc, err := readVaultInput(path)
 	if err != nil {
-		return fmt.Errorf("cannot read password input: %w", err)
+		return fmt.Errorf("cannot read vault %q: %w", path, err)
 	}
-	pwd := string(pwdBytes)
 
-	fmt.Fprintln(os.Stderr) // Newline after password input
+	if vaultDataEnc == nil {
+		return fmt.Errorf("vault %q isn't encrypted", path)
+	}
 
-	vaultData, err := avdu.ReadAndDecryptVaultFile(path, pwd)
+	vaultData, _, err := decryptInput(vaultDataEnc)
 	if err != nil {
 		return fmt.Errorf("cannot decrypt vault %q: %w", path, err)
 	}
@@ -220,3 +746,51 @@ func decryptAction(ctx *cli.Context) error {
 
 	return nil
 }
+
+// readPassword is a helper to prompt for and read the vault password.
+func readPassword() (string, error) {
+	return promptPassword("Enter password: ")
+}
+
+// promptPassword is a helper to display the prompt and read a password.
+//
+// The prompt is written to stderr so it doesn't mix with piped output.
+// The password is read from /dev/tty when stdin isn't a terminal.
+func promptPassword(prompt string) (string, error) {
+	pwdBytes, err := promptPasswordBytes(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pwdBytes), nil
+}
+
+// promptPasswordBytes is a helper to display the prompt and read a password
+// into a byte slice that the caller can wipe.
+func promptPasswordBytes(prompt string) ([]byte, error) {
+	var fd int = int(syscall.Stdin)
+
+	// Read from the terminal directly when the vault is piped to stdin
+	if !term.IsTerminal(fd) {
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			return nil, fmt.Errorf("cannot read password input: %w", err)
+		}
+
+		defer tty.Close()
+
+		fd = int(tty.Fd())
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+
+	pwdBytes, err := term.ReadPassword(fd)
+
+	fmt.Fprintln(os.Stderr) // Ensure there's a newline for the next output
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read password input: %w", err)
+	}
+
+	return pwdBytes, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+func mergeAction(ctx *cli.Context) error {
+	if ctx.NArg() < 2 {
+		return fmt.Errorf("expected at least 2 vault paths, got %v", ctx.NArg())
+	}
+
+	var candidates []avdu.VaultCandidate
+
+	for _, path := range ctx.Args().Slice() {
+		candidate, err := avdu.StatVaultCandidate(path)
+		if err != nil {
+			return fmt.Errorf("cannot read vault %q: %w", path, err)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	var policy vault.ConflictPolicy = vault.ConflictPolicy(ctx.String("policy"))
+
+	// Order the vaults from oldest to newest so later vaults are preferred as newest
+	if policy == vault.PreferNewest {
+		slices.SortStableFunc(candidates, func(a, b avdu.VaultCandidate) int {
+			return a.Timestamp().Compare(b.Timestamp())
+		})
+	}
+
+	var vaults []*vault.Vault
+	var pwd string
+
+	for _, candidate := range candidates {
+		vaultData, err := readAnyVault(candidate.Path, &pwd)
+		if err != nil {
+			return fmt.Errorf("cannot read vault %q: %w", candidate.Path, err)
+		}
+
+		vaults = append(vaults, vaultData)
+	}
+
+	var opts vault.MergeOptions = vault.MergeOptions{
+		Policy:  policy,
+		Resolve: resolveConflict,
+	}
+
+	merged, err := vault.Merge(vaults, opts)
+	if err != nil {
+		return fmt.Errorf("cannot merge vaults: %w", err)
+	}
+
+	var output any = merged
+
+	if ctx.Bool("encrypt") {
+		newPwd, err := promptNewPassword()
+		if err != nil {
+			return err
+		}
+
+		output, err = merged.EncryptWithPassword(newPwd)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeVaultOutput(output, ctx.Path("output"))
+}
+
+// resolveConflict is a helper to prompt for which of the conflicting entries to keep.
+func resolveConflict(conflict vault.Conflict) (vault.Entry, error) {
+	fmt.Fprintln(os.Stderr, "Conflicting entries:")
+	fmt.Fprintf(os.Stderr, "  [1] %v\n", describeEntry(conflict.Existing))
+	fmt.Fprintf(os.Stderr, "  [2] %v\n", describeEntry(conflict.Incoming))
+
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Fprint(os.Stderr, "Keep entry [1/2]: ")
+
+		choice, err := reader.ReadString('\n')
+
+		switch strings.TrimSpace(choice) {
+		case "1":
+			return conflict.Existing, nil
+		case "2":
+			return conflict.Incoming, nil
+		}
+
+		if err != nil {
+			return vault.Entry{}, fmt.Errorf("cannot read choice: %w", err)
+		}
+	}
+}
+
+// describeEntry is a helper to summarize an entry without its secret.
+func describeEntry(entry vault.Entry) string {
+	var outputFormat string = "%v (%v) uuid: %v, type: %v, algo: %v, digits: %v, period: %v, groups: %v"
+
+	var fields []any = []any{
+		entry.Issuer,
+		entry.Name,
+		entry.Uuid,
+		entry.Type,
+		entry.Info.Algo,
+		entry.Info.Digits,
+		entry.Info.Period,
+		entry.Groups,
+	}
+
+	return fmt.Sprintf(outputFormat, fields...)
+}
+
+// promptNewPassword is a helper to read and confirm a new vault password.
+func promptNewPassword() (string, error) {
+	pwd, err := promptPassword("Enter new password: ")
+	if err != nil {
+		return "", err
+	}
+
+	confirm, err := promptPassword("Confirm new password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if pwd == "" || pwd != confirm {
+		return "", errors.New("the passwords are empty or don't match")
+	}
+
+	return pwd, nil
+}
+
+// writeVaultOutput is a helper to write the vault as indented json
+// to the output path or stdout.
+func writeVaultOutput(vaultData any, outputPath string) error {
+	// Marshal with indentation to match Aegis export format
+	output, err := json.MarshalIndent(vaultData, "", "    ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal vault: %w", err)
+	}
+
+	if outputPath == "" {
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if err := os.WriteFile(outputPath, output, 0600); err != nil {
+		return fmt.Errorf("cannot write to %q: %w", outputPath, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Vault written to %s\n", outputPath)
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+func migrateAction(ctx *cli.Context) error {
+	var path string = ctx.Path("path")
+	var version int = ctx.Int("db-version")
+
+	return rewriteVault(path, ctx.Path("output"), func(vaultData *vault.Vault) error {
+		db, err := vaultData.Db.Migrate(version)
+		if err != nil {
+			return fmt.Errorf("cannot migrate vault %q: %w", path, err)
+		}
+
+		vaultData.Db = db
+
+		return nil
+	})
+}
+
+var dbVersionFlag = &cli.IntFlag{
+	Name:  "db-version",
+	Usage: "the database version to write",
+	Value: vault.LatestDbVersion,
+}
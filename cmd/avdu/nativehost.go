@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/sammy-t/avdu/nativehost"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// rulesFile is the file in the user's config directory holding the site rules.
+const rulesFile string = "rules.json"
+
+// nativeHostAction serves native messaging requests from a browser extension
+// on stdin and stdout until the browser closes the connection.
+//
+// The browser's arguments, like the extension's origin, are ignored.
+func nativeHostAction(ctx *cli.Context) error {
+	vaultPath, err := selectVaultPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	if vaultPath == stdinPath {
+		return errors.New("the native host reads requests from stdin, so the vault must be a file")
+	}
+
+	rules, err := readSiteRules(ctx.Path("rules"))
+	if err != nil {
+		return err
+	}
+
+	var host *nativehost.Host = nativehost.NewHost(vaultPath, rules, vault.KeyOptions{})
+
+	defer host.Lock()
+
+	return host.Serve(ctx.Context, os.Stdin, os.Stdout)
+}
+
+// readSiteRules is a helper to read the rules file at the path,
+// or from the user's config directory if it exists when the path is empty.
+func readSiteRules(path string) (nativehost.Rules, error) {
+	if path == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nativehost.Rules{}, nil
+		}
+
+		rules, err := nativehost.ReadRules(filepath.Join(configDir, "avdu", rulesFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nativehost.Rules{}, nil
+		}
+
+		return rules, err
+	}
+
+	rules, err := nativehost.ReadRules(path)
+	if err != nil {
+		return rules, fmt.Errorf("cannot read rules %q: %w", path, err)
+	}
+
+	return rules, nil
+}
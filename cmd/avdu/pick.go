@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	launcherRofi  string = "rofi"  // Rofi's script mode, which runs avdu for the list and again with the selection
+	launcherDmenu string = "dmenu" // Dmenu or a compatible launcher reading lines from stdin
+	launcherFzf   string = "fzf"   // Fzf with a preview of the entry's details
+
+	rofiSelected string = "1" // The ROFI_RETV value when an entry was selected
+)
+
+// clipboardCommands are the commands tried in order to copy to the clipboard.
+var clipboardCommands [][]string = [][]string{
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"pbcopy"},
+}
+
+func pickAction(ctx *cli.Context) error {
+	var launcher string = ctx.String("launcher")
+
+	if launcher == launcherRofi {
+		return rofiAction(ctx)
+	}
+
+	if launcher != launcherDmenu && launcher != launcherFzf {
+		return fmt.Errorf("unsupported launcher %q (want %v, %v, or %v)", launcher, launcherRofi, launcherDmenu, launcherFzf)
+	}
+
+	if ctx.Bool("print") {
+		db, err := readListDb(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(pickLines(db, launcher))
+
+		return nil
+	}
+
+	vaultPath, err := selectVaultPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	vaultData, err := unlockPath(ctx, vaultPath)
+	if err != nil {
+		return err
+	}
+
+	var selection string = ctx.String("selection")
+
+	// Run the launcher unless the selection was passed back
+	if selection == "" {
+		selection, err = runLauncher(launcher, pickLines(vaultData.Db, launcher))
+		if err != nil {
+			return err
+		}
+	}
+
+	entry, err := pickEntry(vaultData.Db, selection)
+	if err != nil {
+		return err
+	}
+
+	return deliverCode(ctx, entry)
+}
+
+// rofiAction is a helper to implement rofi's script mode.
+//
+// Rofi runs the script without arguments to list the entries, then runs it
+// again with the selected line and the entry's uuid in ROFI_INFO.
+func rofiAction(ctx *cli.Context) error {
+	if os.Getenv("ROFI_RETV") != rofiSelected {
+		db, err := readListDb(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print("\x00prompt\x1favdu\n")
+		fmt.Print("\x00no-custom\x1ftrue\n")
+		fmt.Print(pickLines(db, launcherRofi))
+
+		return nil
+	}
+
+	vaultPath, err := selectVaultPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	vaultData, err := unlockPath(ctx, vaultPath)
+	if err != nil {
+		return err
+	}
+
+	var selection string = os.Getenv("ROFI_INFO")
+
+	if selection == "" {
+		selection = ctx.Args().First()
+	}
+
+	entry, err := pickEntry(vaultData.Db, selection)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("copy") {
+		return deliverCode(ctx, entry)
+	}
+
+	pass, err := codeFor(entry)
+	if err != nil {
+		return err
+	}
+
+	// Show the code in rofi's message bar since stdout is read as menu rows
+	fmt.Printf("\x00message\x1f%v (%v): %v\n", entry.Issuer, entry.Name, pass)
+
+	recordUsage(ctx, entry)
+
+	return nil
+}
+
+// pickLines is a helper to format a line per entry for the launcher.
+// Each line includes the entry's uuid so entries with the same issuer
+// and name can be told apart.
+func pickLines(db vault.Db, launcher string) string {
+	var builder strings.Builder
+
+	for _, entry := range db.Entries {
+		var label string = fmt.Sprintf("%v (%v)", entry.Issuer, entry.Name)
+
+		switch launcher {
+		case launcherRofi:
+			// The info field is passed back in ROFI_INFO when the row is selected
+			fmt.Fprintf(&builder, "%v\x00info\x1f%v\n", label, entry.Uuid)
+		case launcherFzf:
+			// The uuid and details fields are hidden from the list but used by the preview
+			var details string = fmt.Sprintf("type: %v  algo: %v  digits: %v  period: %vs  groups: %v",
+				entry.Type, entry.Info.Algo, entry.Info.Digits, entry.Info.Period, strings.Join(db.EntryGroupNames(entry), ", "))
+
+			fmt.Fprintf(&builder, "%v\t%v\t%v\n", entry.Uuid, label, details)
+		default:
+			fmt.Fprintf(&builder, "%v  %v\n", label, entry.Uuid)
+		}
+	}
+
+	return builder.String()
+}
+
+// pickEntry is a helper to find the entry for a launcher's selected line,
+// which contains the entry's uuid, or for a query like the show command takes.
+func pickEntry(db vault.Db, selection string) (vault.Entry, error) {
+	selection = strings.TrimSpace(selection)
+
+	if selection == "" {
+		return vault.Entry{}, errors.New("no entry was selected")
+	}
+
+	for _, field := range strings.Fields(selection) {
+		for _, entry := range db.Entries {
+			if entry.Uuid == field {
+				return entry, nil
+			}
+		}
+	}
+
+	return db.FindEntry(selection)
+}
+
+// runLauncher is a helper to run the launcher with the lines as its input
+// and return the selected line.
+func runLauncher(launcher string, lines string) (string, error) {
+	var args []string = []string{launcher}
+
+	if launcher == launcherDmenu {
+		args = append(args, "-i", "-p", "avdu")
+	} else {
+		args = append(args, "--delimiter", "\t", "--with-nth", "2", "--preview", "echo {3}", "--preview-window", "down:1")
+	}
+
+	var output bytes.Buffer
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(lines)
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("cannot run %v: %w", launcher, err)
+	}
+
+	return output.String(), nil
+}
+
+// deliverCode is a helper to copy the entry's code to the clipboard
+// when requested, otherwise print it.
+func deliverCode(ctx *cli.Context, entry vault.Entry) error {
+	pass, err := codeFor(entry)
+	if err != nil {
+		return err
+	}
+
+	if !ctx.Bool("copy") {
+		fmt.Println(pass)
+		recordUsage(ctx, entry)
+
+		return nil
+	}
+
+	if err := copyToClipboard(pass); err != nil {
+		return err
+	}
+
+	recordUsage(ctx, entry)
+
+	fmt.Fprintf(os.Stderr, "Copied the code for %v (%v)\n", entry.Issuer, entry.Name)
+
+	return nil
+}
+
+// codeFor is a helper to generate the entry's current code.
+func codeFor(entry vault.Entry) (string, error) {
+	// HOTP codes depend on a counter that avdu doesn't update
+	if entry.Type == "hotp" {
+		return "", fmt.Errorf("cannot generate a code for %v (%v): hotp entries aren't supported", entry.Issuer, entry.Name)
+	}
+
+	pass, err := avdu.GetOTP(entry)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate a code for %v (%v): %w", entry.Issuer, entry.Name, err)
+	}
+
+	return pass.String(), nil
+}
+
+// copyToClipboard is a helper to copy the text with the first available clipboard command.
+func copyToClipboard(text string) error {
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("cannot copy with %v: %w", args[0], err)
+		}
+
+		return nil
+	}
+
+	return errors.New("cannot copy: no clipboard command found (wl-copy, xclip, xsel, or pbcopy)")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// pickDb has two entries with the same issuer and name that only their uuids tell apart.
+var pickDb vault.Db = vault.Db{
+	Version: vault.LatestDbVersion,
+	Entries: []vault.Entry{
+		{Type: "totp", Uuid: "3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d", Issuer: "GitHub", Name: "James", Info: vault.Info{Algo: "SHA1", Digits: 6, Period: 30}},
+		{Type: "totp", Uuid: "84b55971-a3d2-4173-a5bb-0aea113dbc17", Issuer: "GitHub", Name: "James", Info: vault.Info{Algo: "SHA256", Digits: 8, Period: 30}},
+		{Type: "steam", Uuid: "5b11ae3b-6fc3-4d46-8ca7-cf0aea7de920", Issuer: "Boeing", Name: "Sophia", Info: vault.Info{Algo: "SHA1", Digits: 5, Period: 30}},
+	},
+}
+
+var vectorsPickLines = []struct {
+	launcher string
+	want     string // The first line
+}{
+	{launcherRofi, "GitHub (James)\x00info\x1f3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d"},
+	{launcherDmenu, "GitHub (James)  3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d"},
+	{launcherFzf, "3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d\tGitHub (James)\ttype: totp  algo: SHA1  digits: 6  period: 30s  groups: "},
+}
+
+func TestPickLines(t *testing.T) {
+	for i, vector := range vectorsPickLines {
+		var lines []string = strings.Split(strings.TrimSuffix(pickLines(pickDb, vector.launcher), "\n"), "\n")
+
+		if len(lines) != len(pickDb.Entries) || lines[0] != vector.want {
+			t.Fatalf("[%v] pickLines(%v) = %q; want %v lines starting with %q", i, vector.launcher, lines, len(pickDb.Entries), vector.want)
+		}
+
+		// The selected line resolves to its own entry even when another has the same issuer and name
+		for j, line := range lines {
+			var selection string = line
+
+			// Rofi passes back the row's info field, the uuid
+			if vector.launcher == launcherRofi {
+				_, selection, _ = strings.Cut(line, "\x1f")
+			}
+
+			entry, err := pickEntry(pickDb, selection)
+			if err != nil || entry.Uuid != pickDb.Entries[j].Uuid {
+				t.Fatalf("[%v] pickEntry(%q) = %v, %v; want %v", i, selection, entry.Uuid, err, pickDb.Entries[j].Uuid)
+			}
+		}
+	}
+}
+
+var vectorsPickEntry = []struct {
+	selection string
+	uuid      string // The selected entry's uuid, empty if it's an error
+}{
+	{"84b55971-a3d2-4173-a5bb-0aea113dbc17", "84b55971-a3d2-4173-a5bb-0aea113dbc17"},
+	{"  GitHub (James)  84b55971-a3d2-4173-a5bb-0aea113dbc17\n", "84b55971-a3d2-4173-a5bb-0aea113dbc17"},
+	{"boeing", "5b11ae3b-6fc3-4d46-8ca7-cf0aea7de920"},
+	{"GitHub (James)", ""},
+	{"missing", ""},
+	{"\n", ""},
+}
+
+func TestPickEntry(t *testing.T) {
+	for i, vector := range vectorsPickEntry {
+		entry, err := pickEntry(pickDb, vector.selection)
+
+		if vector.uuid == "" {
+			if err == nil {
+				t.Fatalf("[%v] pickEntry(%q) = %v; want error", i, vector.selection, entry.Uuid)
+			}
+
+			continue
+		}
+
+		if err != nil || entry.Uuid != vector.uuid {
+			t.Fatalf("[%v] pickEntry(%q) = %v, %v; want %v, nil", i, vector.selection, entry.Uuid, err, vector.uuid)
+		}
+	}
+}
+
+func TestRofiAction(t *testing.T) {
+	var set *flag.FlagSet = flag.NewFlagSet("pick", flag.ContinueOnError)
+
+	set.String("path", "../../test/data/aegis_plain.json", "")
+	set.String("launcher", launcherRofi, "")
+
+	var ctx *cli.Context = cli.NewContext(cli.NewApp(), set, nil)
+
+	// Without a selection the rows are listed with their uuids as info
+	t.Setenv("ROFI_RETV", "0")
+
+	output := captureStdout(t, func() error { return rofiAction(ctx) })
+
+	if !strings.HasPrefix(output, "\x00prompt\x1favdu\n") || !strings.Contains(output, "Boeing (Sophia)\x00info\x1f5b11ae3b-6fc3-4d46-8ca7-cf0aea7de920\n") {
+		t.Fatalf("rofiAction() = %q; want the prompt and entry rows", output)
+	}
+
+	// With a selection the code is shown in the message bar
+	t.Setenv("ROFI_RETV", rofiSelected)
+	t.Setenv("ROFI_INFO", "5b11ae3b-6fc3-4d46-8ca7-cf0aea7de920")
+
+	output = captureStdout(t, func() error { return rofiAction(ctx) })
+
+	if !strings.HasPrefix(output, "\x00message\x1fBoeing (Sophia): ") || len(strings.TrimSpace(output)) != len("\x00message\x1fBoeing (Sophia): ")+5 {
+		t.Fatalf("rofiAction() = %q; want the steam code in the message", output)
+	}
+}
+
+// captureStdout is a helper to return what the function prints to stdout.
+func captureStdout(t *testing.T, print func() error) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout *os.File = os.Stdout
+
+	os.Stdout = w
+	err = print()
+	os.Stdout = stdout
+
+	w.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(output)
+}
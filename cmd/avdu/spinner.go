@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"golang.org/x/term"
+)
+
+var spinnerFrames []string = []string{"|", "/", "-", "\\"}
+
+// spinner displays an animated status line while a slow operation runs.
+type spinner struct {
+	w       io.Writer
+	mu      sync.Mutex
+	message string
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newSpinner creates a spinner that writes the message to w.
+func newSpinner(w io.Writer, message string) *spinner {
+	return &spinner{w: w, message: message, done: make(chan struct{})}
+}
+
+// start begins animating the spinner.
+func (s *spinner) start() {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		var ticker *time.Ticker = time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		for i := 0; ; i++ {
+			s.mu.Lock()
+			fmt.Fprintf(s.w, "\r%v %v ", spinnerFrames[i%len(spinnerFrames)], s.message)
+			s.mu.Unlock()
+
+			select {
+			case <-s.done:
+				fmt.Fprint(s.w, "\r\033[K") // Clear the line
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// update replaces the spinner's message.
+func (s *spinner) update(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.message = message
+}
+
+// stop clears the spinner and waits for it to finish.
+func (s *spinner) stop() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+// unlockVault is a helper to decrypt the vault, trying the password slots
+// in parallel while showing a spinner on stderr.
+//
+// An interrupt cancels the search for the master key.
+func unlockVault(ctx context.Context, file *avdu.VaultFile, pwd string) (*vault.Vault, error) {
+	if !file.Encrypted() {
+		return file.Vault()
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	var opts vault.KeyOptions
+
+	// Only animate when a person is watching
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		var spin *spinner = newSpinner(os.Stderr, "Deriving key")
+
+		opts.Progress = func(progress vault.KeyProgress) {
+			spin.update(fmt.Sprintf("Deriving key (%v/%v slots tried)", progress.Finished, progress.Total))
+		}
+
+		spin.start()
+		defer spin.stop()
+	}
+
+	return file.UnlockContext(ctx, pwd, opts)
+}
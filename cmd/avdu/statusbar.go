@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// statusEntry is an entry's code, or the reason it failed, shown in a status bar.
+type statusEntry struct {
+	entry vault.Entry
+	code  string
+	err   error
+}
+
+// statusLine is the data shown by a status bar for one period.
+type statusLine struct {
+	entries   []statusEntry
+	remaining int64 // The millis until the soonest code refreshes
+	period    int64 // The millis in the period of the soonest code to refresh
+}
+
+// statusFormatter formats a line of status bar output.
+type statusFormatter func(line statusLine) string
+
+// statusFormats maps each supported status bar to its formatter.
+var statusFormats map[string]statusFormatter = map[string]statusFormatter{
+	"waybar":   formatWaybar,
+	"i3blocks": formatI3blocks,
+	"polybar":  formatPolybar,
+	"tmux":     formatTmux,
+}
+
+func statusbarAction(ctx *cli.Context) error {
+	var format string = ctx.String("format")
+
+	formatter, ok := statusFormats[format]
+	if !ok {
+		return fmt.Errorf("unsupported status bar format %q (want %v)", format, strings.Join(slices.Sorted(maps.Keys(statusFormats)), ", "))
+	}
+
+	vaultPath, err := selectVaultPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	vaultData, err := unlockPath(ctx, vaultPath)
+	if err != nil {
+		return err
+	}
+
+	var entries []vault.Entry = vaultData.Db.Entries
+
+	if ctx.Args().Present() {
+		entries = nil
+
+		for _, query := range ctx.Args().Slice() {
+			entry, err := vaultData.Db.FindEntry(query)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	signalCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt)
+	defer stop()
+
+	for {
+		fmt.Println(formatter(newStatusLine(entries)))
+
+		if ctx.Bool("once") {
+			return nil
+		}
+
+		// Wait for the next period boundary instead of polling
+		var timer *time.Timer = time.NewTimer(time.Duration(avdu.NextRefresh(entries)) * time.Millisecond)
+
+		select {
+		case <-signalCtx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// newStatusLine is a helper to generate the entries' codes
+// and find the time until the soonest one refreshes.
+func newStatusLine(entries []vault.Entry) statusLine {
+	var line statusLine
+
+	line.remaining, line.period = avdu.NextRefreshAt(entries, time.Now().UnixMilli())
+
+	for _, entry := range entries {
+		var status statusEntry = statusEntry{entry: entry}
+
+		pass, err := avdu.GetOTP(entry)
+		if err != nil {
+			status.err = err
+		} else {
+			status.code = pass.String()
+		}
+
+		line.entries = append(line.entries, status)
+	}
+
+	return line
+}
+
+// waybarOutput is the json waybar reads for a custom module with return-type json.
+type waybarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"` // The time remaining in the period
+}
+
+// formatWaybar is a helper to format the line as waybar json.
+func formatWaybar(line statusLine) string {
+	var output waybarOutput = waybarOutput{Class: "ok"}
+
+	var texts []string
+	var tooltips []string
+
+	for _, status := range line.entries {
+		texts = append(texts, statusText(status, "", ""))
+
+		if status.err != nil {
+			output.Class = "error"
+			tooltips = append(tooltips, fmt.Sprintf("%v (%v): %v", status.entry.Issuer, status.entry.Name, status.err))
+		} else {
+			tooltips = append(tooltips, fmt.Sprintf("%v (%v): %v", status.entry.Issuer, status.entry.Name, status.code))
+		}
+	}
+
+	output.Text = strings.Join(texts, "  ")
+	output.Tooltip = strings.Join(tooltips, "\n")
+
+	if line.period > 0 {
+		output.Percentage = int(math.Round(float64(line.remaining) * 100 / float64(line.period)))
+	}
+
+	// Strings and ints always marshal so the error isn't checked
+	data, _ := json.Marshal(output)
+
+	return string(data)
+}
+
+// formatI3blocks is a helper to format the line as an i3blocks persistent block's full text.
+func formatI3blocks(line statusLine) string {
+	return joinStatus(line, "", "")
+}
+
+// formatPolybar is a helper to format the line for a polybar script module with tail enabled.
+func formatPolybar(line statusLine) string {
+	return joinStatus(line, "%{F#e06c75}", "%{F-}")
+}
+
+// formatTmux is a helper to format the line for a tmux #() status command.
+func formatTmux(line statusLine) string {
+	return joinStatus(line, "#[fg=red]", "#[default]")
+}
+
+// joinStatus is a helper to join the entries' text,
+// wrapping failed entries in the error style.
+func joinStatus(line statusLine, errStart string, errEnd string) string {
+	var texts []string
+
+	for _, status := range line.entries {
+		texts = append(texts, statusText(status, errStart, errEnd))
+	}
+
+	return strings.Join(texts, "  ")
+}
+
+// statusText is a helper to describe an entry's code,
+// wrapping it in the error style if it failed.
+func statusText(status statusEntry, errStart string, errEnd string) string {
+	if status.err != nil {
+		return fmt.Sprintf("%v%v: error%v", errStart, status.entry.Issuer, errEnd)
+	}
+
+	return fmt.Sprintf("%v: %v", status.entry.Issuer, status.code)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+// okLine has the codes of two entries with 7.5 of 30 seconds remaining.
+var okLine statusLine = statusLine{
+	entries: []statusEntry{
+		{entry: vault.Entry{Issuer: "Deno", Name: "Mason"}, code: "123456"},
+		{entry: vault.Entry{Issuer: "Boeing", Name: "Sophia"}, code: "7XK2M"},
+	},
+	remaining: 7500,
+	period:    30000,
+}
+
+// errLine has a code and an entry that failed.
+var errLine statusLine = statusLine{
+	entries: []statusEntry{
+		{entry: vault.Entry{Issuer: "Deno", Name: "Mason"}, code: "123456"},
+		{entry: vault.Entry{Issuer: "SPDX", Name: "James"}, err: errors.New("unsupported algorithm")},
+	},
+	remaining: 20000,
+	period:    60000,
+}
+
+var vectorsWaybar = []struct {
+	line statusLine
+	want waybarOutput
+}{
+	{okLine, waybarOutput{
+		Text:       "Deno: 123456  Boeing: 7XK2M",
+		Tooltip:    "Deno (Mason): 123456\nBoeing (Sophia): 7XK2M",
+		Class:      "ok",
+		Percentage: 25,
+	}},
+	{errLine, waybarOutput{
+		Text:       "Deno: 123456  SPDX: error",
+		Tooltip:    "Deno (Mason): 123456\nSPDX (James): unsupported algorithm",
+		Class:      "error",
+		Percentage: 33,
+	}},
+	{statusLine{}, waybarOutput{Class: "ok"}},
+}
+
+func TestFormatWaybar(t *testing.T) {
+	for i, vector := range vectorsWaybar {
+		var output string = formatWaybar(vector.line)
+
+		var fields map[string]any
+
+		if err := json.Unmarshal([]byte(output), &fields); err != nil {
+			t.Fatalf("[%v] formatWaybar() = %v; want json: %v", i, output, err)
+		}
+
+		for _, field := range []string{"text", "tooltip", "class", "percentage"} {
+			if _, ok := fields[field]; !ok {
+				t.Fatalf("[%v] formatWaybar() = %v; want the %q field", i, output, field)
+			}
+		}
+
+		var got waybarOutput
+
+		if err := json.Unmarshal([]byte(output), &got); err != nil || got != vector.want {
+			t.Fatalf("[%v] formatWaybar() = %+v; want %+v", i, got, vector.want)
+		}
+	}
+}
+
+var vectorsStatusFormat = []struct {
+	format string
+	line   statusLine
+	want   string
+}{
+	{"i3blocks", okLine, "Deno: 123456  Boeing: 7XK2M"},
+	{"i3blocks", errLine, "Deno: 123456  SPDX: error"},
+	{"polybar", okLine, "Deno: 123456  Boeing: 7XK2M"},
+	{"polybar", errLine, "Deno: 123456  %{F#e06c75}SPDX: error%{F-}"},
+	{"tmux", okLine, "Deno: 123456  Boeing: 7XK2M"},
+	{"tmux", errLine, "Deno: 123456  #[fg=red]SPDX: error#[default]"},
+	{"tmux", statusLine{}, ""},
+}
+
+func TestStatusFormats(t *testing.T) {
+	for i, vector := range vectorsStatusFormat {
+		if got := statusFormats[vector.format](vector.line); got != vector.want {
+			t.Fatalf("[%v] %v formatter = %q; want %q", i, vector.format, got, vector.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// usageLogFile is the file in the user's config directory holding the usage log.
+const usageLogFile string = "usage.log"
+
+var recordUsageFlag = &cli.BoolFlag{
+	Name:  "record-usage",
+	Usage: "record the use in the local usage log (entry uuids and times, never codes or secrets)",
+}
+
+func recentAction(ctx *cli.Context) error {
+	logPath, err := usageLogPath()
+	if err != nil {
+		return err
+	}
+
+	events, err := avdu.ReadUsageLog(logPath)
+	if err != nil {
+		return err
+	}
+
+	db, err := readListDb(ctx)
+	if err != nil {
+		return err
+	}
+
+	var entries map[string]vault.Entry = make(map[string]vault.Entry)
+
+	for _, entry := range db.Entries {
+		entries[entry.Uuid] = entry
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "ISSUER\tNAME\tLAST USED\tUSES\tUUID")
+
+	var shown int
+
+	for _, recent := range avdu.RecentUsage(events) {
+		// The log covers every vault, so skip the entries of others
+		entry, ok := entries[recent.Uuid]
+		if !ok {
+			continue
+		}
+
+		if limit := ctx.Int("limit"); limit > 0 && shown >= limit {
+			break
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", entry.Issuer, entry.Name,
+			recent.LastUsed.Local().Format(timeFmt), recent.Uses, entry.Uuid)
+
+		shown++
+	}
+
+	return writer.Flush()
+}
+
+func usageImportAction(ctx *cli.Context) error {
+	var prefsPath string = ctx.Args().First()
+
+	if prefsPath == "" {
+		return errors.New("the path to the Aegis preferences file is required")
+	}
+
+	file, err := os.Open(prefsPath)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	counts, err := avdu.ReadAegisUsageCounts(file)
+	if err != nil {
+		return fmt.Errorf("cannot import usage from %q: %w", prefsPath, err)
+	}
+
+	logPath, err := usageLogPath()
+	if err != nil {
+		return err
+	}
+
+	if err := avdu.ImportUsage(logPath, avdu.UsageSourceAegis, counts, time.Now()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Imported the usage counts of %v entries\n", len(counts))
+
+	return nil
+}
+
+// usageLogPath is a helper to get the path of the usage log in the user's config directory.
+func usageLogPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "avdu", usageLogFile), nil
+}
+
+// readUsageCounts is a helper to read the entries' usage counts from the usage log.
+func readUsageCounts() (map[string]int, error) {
+	logPath, err := usageLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := avdu.ReadUsageLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return avdu.UsageCounts(events), nil
+}
+
+// recordUsage is a helper to log a use of the entry when the record usage flag is set.
+// A failure to record is only reported since the code was already delivered.
+func recordUsage(ctx *cli.Context, entry vault.Entry) {
+	if !ctx.Bool("record-usage") {
+		return
+	}
+
+	logPath, err := usageLogPath()
+	if err == nil {
+		err = avdu.AppendUsage(logPath, entry.Uuid, time.Now())
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot record usage: %v\n", err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sammy-t/avdu"
+	"github.com/urfave/cli/v2"
+)
+
+var selectFlag = &cli.StringFlag{
+	Name:  "select",
+	Usage: "policy for choosing the vault in a directory (filename, mtime, version, index)",
+	Value: string(avdu.SelectFilenameTime),
+}
+
+var indexFlag = &cli.IntFlag{
+	Name:  "index",
+	Usage: "the candidate index used by the 'index' select policy, newest first",
+}
+
+func listVaultsAction(ctx *cli.Context) error {
+	var path string = ctx.Path("path")
+
+	policy, err := avdu.ParseSelectPolicy(ctx.String("select"))
+	if err != nil {
+		return err
+	}
+
+	candidates, err := avdu.ListVaultCandidates(path)
+	if err != nil {
+		return fmt.Errorf("cannot list vaults in %q: %w", path, err)
+	}
+
+	selected, err := avdu.SelectVault(candidates, policy, ctx.Int("index"))
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "\tINDEX\tNAME\tFILENAME TIME\tMODIFIED")
+
+	for i, candidate := range candidates {
+		var marker string
+
+		if candidate.Path == selected.Path {
+			marker = "*"
+		}
+
+		var fileTime string = "-"
+
+		if !candidate.Time.IsZero() {
+			fileTime = candidate.Time.Format(timeFmt)
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", marker, i, candidate.Name, fileTime, candidate.ModTime.Format(timeFmt))
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSelected (%v): %v\n", policy, selected.Path)
+
+	return nil
+}
+
+func pruneAction(ctx *cli.Context) error {
+	var path string = ctx.Path("path")
+
+	var opts avdu.PruneOptions = avdu.PruneOptions{
+		KeepLast:      ctx.Int("keep-last"),
+		KeepDaily:     ctx.Int("keep-daily"),
+		KeepWeekly:    ctx.Int("keep-weekly"),
+		KeepMonthly:   ctx.Int("keep-monthly"),
+		Dedupe:        ctx.Bool("dedupe"),
+		AllowUnparsed: ctx.Bool("allow-unparsed"),
+	}
+
+	if ctx.Bool("encrypted") {
+		pwd, err := readPassword()
+		if err != nil {
+			return err
+		}
+
+		opts.Password = pwd
+	}
+
+	plan, err := avdu.PlanPrune(path, opts)
+	if err != nil {
+		return fmt.Errorf("cannot plan pruning %q: %w", path, err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "ACTION\tNAME\tREASON")
+
+	for _, decision := range plan {
+		var action string = "keep"
+
+		if !decision.Keep {
+			action = "remove"
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\n", action, decision.Candidate.Name, decision.Reason)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	var removed int = len(plan.Removed())
+
+	if ctx.Bool("dry-run") {
+		fmt.Printf("\nDry run: %v of %v vaults would be removed\n", removed, len(plan))
+		return nil
+	}
+
+	if err := plan.Apply(); err != nil {
+		return fmt.Errorf("cannot remove vaults: %w", err)
+	}
+
+	fmt.Printf("\nRemoved %v of %v vaults\n", removed, len(plan))
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+	"github.com/urfave/cli/v2"
+)
+
+// otpGenerator generates the OTP results for the displayed vault.
+type otpGenerator func(vaultData *vault.Vault) map[string]avdu.OTPResult
+
+// otpView configures how the OTPs are generated and displayed.
+type otpView struct {
+	generate otpGenerator
+	sort     vault.SortOptions
+	grouped  bool
+	filter   string // Matches part of an entry's issuer or name
+	group    string // Matches one of an entry's group names
+}
+
+// newOTPView is a helper to create a view from the display flags.
+func newOTPView(ctx *cli.Context, generate otpGenerator) (otpView, error) {
+	mode, err := vault.ParseSortMode(ctx.String("sort"))
+	if err != nil {
+		return otpView{}, err
+	}
+
+	var view otpView = otpView{
+		generate: generate,
+		sort:     vault.SortOptions{Mode: mode, FavoritesFirst: ctx.Bool("favorites-first")},
+		grouped:  ctx.Bool("grouped"),
+		filter:   ctx.String("filter"),
+		group:    ctx.String("group"),
+	}
+
+	if mode == vault.SortUsage {
+		view.sort.Usage, err = readUsageCounts()
+		if err != nil {
+			return otpView{}, err
+		}
+	}
+
+	return view, nil
+}
+
+// filtered is a helper to return a copy of the vault
+// containing only the entries that match the view's filters.
+func (v otpView) filtered(vaultData *vault.Vault) *vault.Vault {
+	if v.filter == "" && v.group == "" {
+		return vaultData
+	}
+
+	var filteredData vault.Vault = *vaultData
+
+	filteredData.Db.Entries = nil
+
+	for i, meta := range avdu.Metadata(vaultData) {
+		if meta.Matches(v.filter, v.group) {
+			filteredData.Db.Entries = append(filteredData.Db.Entries, vaultData.Db.Entries[i])
+		}
+	}
+
+	return &filteredData
+}
+
+// plainOTPs is a helper to generate the OTP results from the plaintext vault's secrets.
+func plainOTPs(vaultData *vault.Vault) map[string]avdu.OTPResult {
+	// Failed entries are shown inline so the error isn't checked separately
+	results, _ := avdu.GetOTPResults(vaultData)
+
+	return results
+}
+
+var sortFlag = &cli.StringFlag{
+	Name:  "sort",
+	Usage: "the order of the entries (custom, issuer, name, usage)",
+	Value: string(vault.SortCustom),
+}
+
+var favoritesFirstFlag = &cli.BoolFlag{
+	Name:  "favorites-first",
+	Usage: "list favorite entries before the others",
+}
+
+var groupedFlag = &cli.BoolFlag{
+	Name:  "grouped",
+	Usage: "list the entries under headings for their groups",
+}
+
+var filterFlag = &cli.StringFlag{
+	Name:    "filter",
+	Aliases: []string{"f"},
+	Usage:   "only list the entries whose issuer or name contains the text",
+}
+
+var groupFlag = &cli.StringFlag{
+	Name:    "group",
+	Aliases: []string{"g"},
+	Usage:   "only list the entries in the named group",
+}
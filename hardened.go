@@ -0,0 +1,118 @@
+package avdu
+
+import (
+	"errors"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+// HardenedVault is an unlocked encrypted vault that minimizes
+// how long key material and secrets are held in memory.
+//
+// The master key is kept in a buffer that's locked from being swapped to disk
+// where the platform allows it. The vault's metadata is decrypted without
+// its secrets, and the secrets are only decrypted while generating codes
+// then wiped. Close wipes the master key.
+type HardenedVault struct {
+	enc  *vault.VaultEncrypted
+	key  *lockedBuffer
+	meta *vault.Vault
+}
+
+// UnlockHardened decrypts the vault with the password and returns a hardened vault.
+// The password is wiped before returning.
+//
+// Plaintext vaults can't be hardened since their secrets are already in memory.
+func (f *VaultFile) UnlockHardened(pwd []byte) (*HardenedVault, error) {
+	defer vault.Wipe(pwd)
+
+	if f.enc == nil {
+		return nil, errors.New("only encrypted vaults can be hardened")
+	}
+
+	if len(pwd) == 0 {
+		return nil, ErrPasswordRequired
+	}
+
+	masterKey, err := f.enc.FindMasterKeyBytes(pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newLockedBuffer(masterKey)
+	if err != nil {
+		vault.Wipe(masterKey)
+		return nil, err
+	}
+
+	meta, err := f.enc.DecryptMetadata(key.bytes())
+	if err != nil {
+		key.destroy()
+		return nil, err
+	}
+
+	return &HardenedVault{enc: f.enc, key: key, meta: meta}, nil
+}
+
+// Vault returns the plaintext vault with empty entry secrets and PINs.
+func (h *HardenedVault) Vault() *vault.Vault {
+	return h.meta
+}
+
+// MemoryLocked reports whether the master key's memory is locked.
+func (h *HardenedVault) MemoryLocked() bool {
+	return h.key != nil && h.key.locked
+}
+
+// GetOTPResults decrypts the entries' secrets, generates their OTPs,
+// and wipes the secrets before returning.
+//
+// The results match GetOTPResults for the unlocked vault.
+func (h *HardenedVault) GetOTPResults() (map[string]OTPResult, error) {
+	if h.key == nil || h.key.data == nil {
+		return nil, errors.New("the hardened vault is closed")
+	}
+
+	secrets, err := h.enc.DecryptSecrets(h.key.bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	defer vault.WipeSecrets(secrets)
+
+	var results map[string]OTPResult = make(map[string]OTPResult)
+	var errs []error
+
+	for _, secret := range secrets {
+		// The otp package takes the PIN as a string which can't be wiped
+		pass, err := generateOTP(secret.Type, secret.Secret, secret.Algo, secret.Digits, int64(secret.Period), string(secret.Pin))
+		if err != nil {
+			var entry vault.Entry = h.entry(secret.Uuid)
+
+			err = &EntryError{Uuid: secret.Uuid, Issuer: entry.Issuer, Name: entry.Name, Err: err}
+			errs = append(errs, err)
+		}
+
+		results[secret.Uuid] = OTPResult{OTP: pass, Err: err}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// Close wipes and releases the master key.
+func (h *HardenedVault) Close() {
+	if h.key != nil {
+		h.key.destroy()
+	}
+}
+
+// entry is a helper to find the metadata of the entry with the uuid.
+func (h *HardenedVault) entry(uuid string) vault.Entry {
+	for _, entry := range h.meta.Db.Entries {
+		if entry.Uuid == uuid {
+			return entry
+		}
+	}
+
+	return vault.Entry{}
+}
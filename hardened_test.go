@@ -0,0 +1,69 @@
+package avdu_test
+
+import (
+	"testing"
+
+	"github.com/sammy-t/avdu"
+)
+
+func TestUnlockHardened(t *testing.T) {
+	file, err := avdu.Open("test/data/aegis_encrypted.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pwd []byte = []byte("test")
+
+	hardened, err := file.UnlockHardened(pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer hardened.Close()
+
+	if string(pwd) == "test" {
+		t.Fatalf("UnlockHardened() didn't wipe the password")
+	}
+
+	plain, err := file.Unlock("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Generate the plaintext codes on both sides in case a period ends in between
+	before, _ := avdu.GetOTPResults(plain)
+
+	results, err := hardened.GetOTPResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, _ := avdu.GetOTPResults(plain)
+
+	for i, entry := range hardened.Vault().Db.Entries {
+		if entry.Info.Secret != "" {
+			t.Fatalf("[%v] Vault() entry has secret; want none", i)
+		}
+
+		var code string = results[entry.Uuid].OTP.String()
+
+		if code != before[entry.Uuid].OTP.String() && code != after[entry.Uuid].OTP.String() {
+			t.Fatalf("[%v] GetOTPResults() = %v; want %v", i, code, before[entry.Uuid].OTP)
+		}
+	}
+
+	hardened.Close()
+
+	if _, err := hardened.GetOTPResults(); err == nil {
+		t.Fatalf("GetOTPResults() after Close() error = nil; want an error")
+	}
+
+	plainFile, err := avdu.Open("test/data/aegis_plain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := plainFile.UnlockHardened([]byte("test")); err == nil {
+		t.Fatalf("UnlockHardened() on a plaintext vault error = nil; want an error")
+	}
+}
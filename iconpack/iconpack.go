@@ -0,0 +1,172 @@
+// Package iconpack provides functionality for reading Aegis Authenticator
+// icon packs and assigning their icons to vault entries.
+package iconpack
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+// manifestName is the name of the icon pack's manifest file.
+const manifestName string = "pack.json"
+
+// Pack is an Aegis icon pack read from a zip file.
+type Pack struct {
+	Uuid    string `json:"uuid"`
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Icons   []Icon `json:"icons"`
+
+	files  fs.FS
+	closer io.Closer
+}
+
+// Icon describes an icon in the pack and the issuers it's for.
+type Icon struct {
+	Filename string   `json:"filename"`
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Issuer   []string `json:"issuer"`
+
+	patterns []*regexp.Regexp
+}
+
+// Open reads the icon pack zip file at the path.
+// The pack must be closed once its icons are no longer needed.
+func Open(filePath string) (*Pack, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	pack, err := readPack(reader)
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("cannot read icon pack %q: %w", filePath, err)
+	}
+
+	pack.closer = reader
+
+	return pack, nil
+}
+
+// Read reads the icon pack zip from the reader.
+func Read(r io.ReaderAt, size int64) (*Pack, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return readPack(reader)
+}
+
+// Close closes the pack's zip file.
+func (p *Pack) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+
+	return p.closer.Close()
+}
+
+// Match returns the first icon with an issuer matching the issuer.
+//
+// Each issuer of an icon is a plain name that must match
+// the whole issuer, ignoring case.
+func (p *Pack) Match(issuer string) (Icon, bool) {
+	for _, icon := range p.Icons {
+		for _, pattern := range icon.patterns {
+			if pattern.MatchString(issuer) {
+				return icon, true
+			}
+		}
+	}
+
+	return Icon{}, false
+}
+
+// ReadIcon reads the icon's data from the pack and returns it with its mime type.
+func (p *Pack) ReadIcon(icon Icon) ([]byte, string, error) {
+	mime, err := vault.IconMime(icon.Filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := fs.ReadFile(p.files, icon.Filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, mime, nil
+}
+
+// Apply assigns the matching icons to the database's entries and returns
+// the uuids of the updated entries. Entries with an icon are only
+// updated if overwrite is set.
+func (p *Pack) Apply(db *vault.Db, overwrite bool) ([]string, error) {
+	var updated []string
+
+	for i := range db.Entries {
+		var entry *vault.Entry = &db.Entries[i]
+
+		if entry.Icon != "" && !overwrite {
+			continue
+		}
+
+		icon, ok := p.Match(entry.Issuer)
+		if !ok {
+			continue
+		}
+
+		data, mime, err := p.ReadIcon(icon)
+		if err != nil {
+			return updated, fmt.Errorf("cannot read icon %q: %w", icon.Filename, err)
+		}
+
+		if err := entry.SetIcon(data, mime); err != nil {
+			return updated, err
+		}
+
+		updated = append(updated, entry.Uuid)
+	}
+
+	return updated, nil
+}
+
+// readPack is a helper to parse the pack's manifest and compile its issuer patterns.
+func readPack(files fs.FS) (*Pack, error) {
+	data, err := fs.ReadFile(files, manifestName)
+	if err != nil {
+		return nil, err
+	}
+
+	var pack Pack
+
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("cannot parse %v: %w", manifestName, err)
+	}
+
+	if len(pack.Icons) == 0 {
+		return nil, errors.New("icon pack has no icons")
+	}
+
+	for i := range pack.Icons {
+		var icon *Icon = &pack.Icons[i]
+
+		// Issuers are plain names, so characters like "+" in "C++" are matched literally
+		for _, issuer := range icon.Issuer {
+			icon.patterns = append(icon.patterns, regexp.MustCompile(`(?i)^`+regexp.QuoteMeta(issuer)+`$`))
+		}
+	}
+
+	pack.files = files
+
+	return &pack, nil
+}
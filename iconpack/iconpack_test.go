@@ -0,0 +1,81 @@
+package iconpack_test
+
+import (
+	"testing"
+
+	"github.com/sammy-t/avdu/iconpack"
+	"github.com/sammy-t/avdu/vault"
+)
+
+const packPath string = "../test/data/iconpack.zip"
+
+var vectorsMatch = []struct {
+	issuer   string
+	filename string
+}{
+	{"Deno", "d/deno.svg"},
+	{"DENO", "d/deno.svg"},
+	{"Airbnb", "a/air.png"},
+	{"Air Canada", "a/air.png"},
+	{"deno.land", "d/deno.svg"},
+	{"denoxland", ""},
+	{"C++", "a/air.png"},
+	{"Cc", ""},
+	{"Deno Land", ""},
+	{"Issuu", ""},
+}
+
+func TestMatch(t *testing.T) {
+	pack, err := iconpack.Open(packPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer pack.Close()
+
+	for i, vector := range vectorsMatch {
+		icon, ok := pack.Match(vector.issuer)
+
+		if ok != (vector.filename != "") || icon.Filename != vector.filename {
+			t.Fatalf("[%v] Match(%v) = %v, %v; want %v", i, vector.issuer, icon.Filename, ok, vector.filename)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	pack, err := iconpack.Open(packPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer pack.Close()
+
+	var db vault.Db = vault.Db{
+		Version: vault.LatestDbVersion,
+		Entries: []vault.Entry{
+			{Uuid: "1", Issuer: "Deno"},
+			{Uuid: "2", Issuer: "Airbnb"},
+			{Uuid: "3", Issuer: "Issuu"},
+			{Uuid: "4", Issuer: "Air Canada", Icon: "existing"},
+		},
+	}
+
+	updated, err := pack.Apply(&db, false)
+	if err != nil || len(updated) != 2 || updated[0] != "1" || updated[1] != "2" {
+		t.Fatalf("Apply() = %v, %v; want [1 2], nil", updated, err)
+	}
+
+	for i, entry := range db.Entries[:2] {
+		if _, err := entry.DecodeIcon(); err != nil {
+			t.Fatalf("[%v] DecodeIcon() after Apply() error = %v; want nil", i, err)
+		}
+	}
+
+	if db.Entries[3].Icon != "existing" {
+		t.Fatalf("Apply() without overwrite replaced an existing icon")
+	}
+
+	if updated, err := pack.Apply(&db, true); err != nil || len(updated) != 3 {
+		t.Fatalf("Apply() with overwrite = %v, %v; want 3 updated entries", updated, err)
+	}
+}
@@ -0,0 +1,335 @@
+package avdu
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+const (
+	IndexExtension string = ".avdu-index" // Appended to the vault's file name for its sidecar index
+	IndexVersion   int    = 1             // The latest index file version
+
+	indexKeyInfo string = "avdu metadata index" // Separates the index key from other keys derived from the master key
+	indexKeyLen  int    = 32                    // The index key length in bytes
+)
+
+// ErrIndexStale is returned when an index was written for another version of the vault.
+var ErrIndexStale = errors.New("the index doesn't match the vault")
+
+// ErrIndexEncrypted is returned when an encrypted index is read without the master key.
+var ErrIndexEncrypted = errors.New("the index is encrypted")
+
+// Index lists the entries of an encrypted vault without their secrets
+// so they can be listed without decrypting the vault.
+//
+// An index is tied to the vault's params nonce, which changes
+// every time the vault is re-encrypted.
+type Index struct {
+	VaultNonce string          // The params nonce of the vault the index was written for
+	Entries    []EntryMetadata // The metadata of the vault's entries
+}
+
+// indexFile is the stored form of an index.
+//
+// Encrypted indexes store the entries as base64 data sealed with AES-GCM
+// with the nonce and tag in params, like an encrypted vault's db.
+type indexFile struct {
+	Version    int             `json:"version"`
+	VaultNonce string          `json:"vault_nonce"`
+	Params     *vault.Params   `json:"params,omitempty"`
+	Data       string          `json:"data,omitempty"`
+	Entries    []EntryMetadata `json:"entries,omitempty"`
+}
+
+// IndexPath returns the path of the sidecar index for the vault file at the path.
+func IndexPath(vaultPath string) string {
+	return vaultPath + IndexExtension
+}
+
+// NewIndex creates an index of the decrypted vault's entries
+// for the encrypted vault it was decrypted from.
+func NewIndex(vaultDataEnc *vault.VaultEncrypted, vaultData *vault.Vault) Index {
+	return Index{VaultNonce: vaultDataEnc.Header.Params.Nonce, Entries: Metadata(vaultData)}
+}
+
+// Matches reports whether the index was written for the encrypted vault's current contents.
+func (i Index) Matches(vaultDataEnc *vault.VaultEncrypted) bool {
+	return i.VaultNonce != "" && i.VaultNonce == vaultDataEnc.Header.Params.Nonce
+}
+
+// Db returns a database of the indexed entries without their secrets.
+//
+// Since the index stores group names, each group's name is used as its uuid.
+func (i Index) Db() vault.Db {
+	var db vault.Db = vault.Db{Version: vault.LatestDbVersion}
+
+	for _, meta := range i.Entries {
+		var entry vault.Entry = vault.Entry{
+			Type:     meta.Type,
+			Uuid:     meta.Uuid,
+			Name:     meta.Name,
+			Issuer:   meta.Issuer,
+			Favorite: meta.Favorite,
+			Info:     vault.Info{Algo: meta.Algo, Digits: meta.Digits, Period: meta.Period},
+			Groups:   meta.Groups,
+		}
+
+		for _, name := range meta.Groups {
+			if !slices.ContainsFunc(db.Groups, func(group vault.Group) bool { return group.Uuid == name }) {
+				db.Groups = append(db.Groups, vault.Group{Uuid: name, Name: name})
+			}
+		}
+
+		db.Entries = append(db.Entries, entry)
+	}
+
+	return db
+}
+
+// MarshalIndex encodes the index, encrypting it with a key derived from the master key.
+//
+// A nil master key stores the index in plaintext, which exposes
+// the issuers, names, and groups to anyone who can read the file.
+func MarshalIndex(index Index, masterKey []byte) ([]byte, error) {
+	var file indexFile = indexFile{Version: IndexVersion, VaultNonce: index.VaultNonce}
+
+	if masterKey == nil {
+		file.Entries = index.Entries
+
+		return json.MarshalIndent(file, "", "    ")
+	}
+
+	plaintext, err := json.Marshal(index.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	aesgcm, err := indexCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce []byte = make([]byte, aesgcm.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// Bind the index to the vault's nonce so it can't be passed off as another version's
+	var sealed []byte = aesgcm.Seal(nil, nonce, plaintext, []byte(index.VaultNonce))
+	var tagStart int = len(sealed) - aesgcm.Overhead()
+
+	file.Params = &vault.Params{Nonce: hex.EncodeToString(nonce), Tag: hex.EncodeToString(sealed[tagStart:])}
+	file.Data = base64.StdEncoding.EncodeToString(sealed[:tagStart])
+
+	return json.MarshalIndent(file, "", "    ")
+}
+
+// UnmarshalIndex decodes the index, decrypting it with a key derived from the master key.
+//
+// ErrIndexEncrypted is returned for encrypted indexes when the master key is nil.
+func UnmarshalIndex(data []byte, masterKey []byte) (Index, error) {
+	var file indexFile
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Index{}, err
+	}
+
+	if file.Version < 1 || file.Version > IndexVersion {
+		return Index{}, fmt.Errorf("unsupported index version %v", file.Version)
+	}
+
+	var index Index = Index{VaultNonce: file.VaultNonce, Entries: file.Entries}
+
+	if file.Params == nil {
+		return index, nil
+	}
+
+	if masterKey == nil {
+		return Index{}, ErrIndexEncrypted
+	}
+
+	aesgcm, err := indexCipher(masterKey)
+	if err != nil {
+		return Index{}, err
+	}
+
+	nonce, err := hex.DecodeString(file.Params.Nonce)
+	if err != nil {
+		return Index{}, err
+	}
+
+	tag, err := hex.DecodeString(file.Params.Tag)
+	if err != nil {
+		return Index{}, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		return Index{}, err
+	}
+
+	if len(nonce) != aesgcm.NonceSize() {
+		return Index{}, errors.New("invalid index nonce length")
+	}
+
+	plaintext, err := aesgcm.Open(nil, nonce, append(sealed, tag...), []byte(file.VaultNonce))
+	if err != nil {
+		return Index{}, err
+	}
+
+	if err := json.Unmarshal(plaintext, &index.Entries); err != nil {
+		return Index{}, err
+	}
+
+	return index, nil
+}
+
+// WriteIndexFile writes the index to the file at the path, encrypting it
+// with a key derived from the master key unless the master key is nil.
+//
+// The file is replaced atomically so a reader never sees a partial index.
+func WriteIndexFile(filePath string, index Index, masterKey []byte) error {
+	data, err := MarshalIndex(index, masterKey)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filePath, data)
+}
+
+// ReadIndexFile reads the index from the file at the path,
+// decrypting it with a key derived from the master key if it's encrypted.
+func ReadIndexFile(filePath string, masterKey []byte) (Index, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Index{}, err
+	}
+
+	return UnmarshalIndex(data, masterKey)
+}
+
+// ReadVaultIndex reads the sidecar index of the encrypted vault file at the path.
+// Only the vault's header is used, so its contents aren't decrypted.
+//
+// ErrIndexStale is returned if the vault has been re-encrypted since the index was written.
+func ReadVaultIndex(vaultPath string, masterKey []byte) (Index, error) {
+	vaultDataEnc, err := ReadVaultFileEnc(vaultPath)
+	if err != nil {
+		return Index{}, err
+	}
+
+	index, err := ReadIndexFile(IndexPath(vaultPath), masterKey)
+	if err != nil {
+		return Index{}, err
+	}
+
+	if !index.Matches(vaultDataEnc) {
+		return Index{}, ErrIndexStale
+	}
+
+	return index, nil
+}
+
+// WriteIndex writes the sidecar index of the unlocked encrypted vault next to
+// the vault file at the path. The index is encrypted with a key derived from
+// the master key unless plaintext is set.
+func (f *VaultFile) WriteIndex(vaultPath string, plaintext bool) error {
+	if f.enc == nil {
+		return errors.New("only encrypted vaults are indexed")
+	}
+
+	if f.plain == nil {
+		return ErrPasswordRequired
+	}
+
+	var masterKey []byte = f.masterKey
+
+	if plaintext {
+		masterKey = nil
+	}
+
+	return WriteIndexFile(IndexPath(vaultPath), NewIndex(f.enc, f.plain), masterKey)
+}
+
+// WriteIndex writes the sidecar index of the hardened vault next to the vault
+// file at the path. The index is encrypted with a key derived from
+// the master key unless plaintext is set.
+func (h *HardenedVault) WriteIndex(vaultPath string, plaintext bool) error {
+	if h.key == nil || h.key.data == nil {
+		return errors.New("the hardened vault is closed")
+	}
+
+	var masterKey []byte = h.key.bytes()
+
+	if plaintext {
+		masterKey = nil
+	}
+
+	return WriteIndexFile(IndexPath(vaultPath), NewIndex(h.enc, h.meta), masterKey)
+}
+
+// WriteIndex writes the sidecar index of the session's saved vault next to
+// the file the session saves to. The index is encrypted with a key derived
+// from the master key unless plaintext is set.
+//
+// Unsaved changes aren't indexed since the index must match the saved vault.
+func (s *Session) WriteIndex(plaintext bool) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.enc == nil {
+		return errors.New("only encrypted vaults are indexed")
+	}
+
+	if s.key == nil {
+		return ErrSessionLocked
+	}
+
+	if s.path == "" {
+		return errors.New("the session has no file path, use SaveAs")
+	}
+
+	saved, err := s.enc.DecryptMetadata(s.key.bytes())
+	if err != nil {
+		return err
+	}
+
+	var masterKey []byte = s.key.bytes()
+
+	if plaintext {
+		masterKey = nil
+	}
+
+	return WriteIndexFile(IndexPath(s.path), NewIndex(s.enc, saved), masterKey)
+}
+
+// indexCipher is a helper to create the AES-GCM cipher for indexes
+// using a key derived from the master key with HKDF.
+func indexCipher(masterKey []byte) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, masterKey, nil, indexKeyInfo, indexKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	defer vault.Wipe(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,130 @@
+package avdu_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestIndex(t *testing.T) {
+	var path string = copyFixture(t, "test/data/aegis_encrypted.json")
+
+	file, err := avdu.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vaultData, err := file.Unlock("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	masterKey, err := file.EncryptedVault().FindMasterKey("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []avdu.EntryMetadata = avdu.Metadata(vaultData)
+
+	for _, plaintext := range []bool{false, true} {
+		if err := file.WriteIndex(path, plaintext); err != nil {
+			t.Fatalf("[plaintext %v] WriteIndex() = %v; want nil", plaintext, err)
+		}
+
+		index, err := avdu.ReadVaultIndex(path, masterKey)
+		if err != nil || !reflect.DeepEqual(index.Entries, want) {
+			t.Fatalf("[plaintext %v] ReadVaultIndex() = %v, %v; want %v, nil", plaintext, index.Entries, err, want)
+		}
+
+		// Only plaintext indexes can be read without the master key
+		_, err = avdu.ReadVaultIndex(path, nil)
+		if plaintext != (err == nil) || !plaintext && !errors.Is(err, avdu.ErrIndexEncrypted) {
+			t.Fatalf("[plaintext %v] ReadVaultIndex(nil) = %v; want an error %v", plaintext, err, !plaintext)
+		}
+	}
+
+	if err := file.WriteIndex(path, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var wrongKey []byte = make([]byte, len(masterKey))
+
+	if _, err := avdu.ReadVaultIndex(path, wrongKey); err == nil {
+		t.Fatal("ReadVaultIndex() with the wrong key = nil; want error")
+	}
+
+	// Saving re-encrypts the vault with a new nonce, invalidating the index
+	session, err := avdu.OpenSession(context.Background(), path, "test", vault.KeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.Close()
+
+	if err := session.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := avdu.ReadVaultIndex(path, masterKey); !errors.Is(err, avdu.ErrIndexStale) {
+		t.Fatalf("ReadVaultIndex() after saving = %v; want %v", err, avdu.ErrIndexStale)
+	}
+
+	if err := session.WriteIndex(false); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := avdu.ReadVaultIndex(path, masterKey)
+	if err != nil || !reflect.DeepEqual(index.Entries, want) {
+		t.Fatalf("ReadVaultIndex() after reindexing = %v, %v; want %v, nil", index.Entries, err, want)
+	}
+}
+
+// copyFixture is a helper to copy the fixture into a temporary directory.
+func copyFixture(t *testing.T, fixture string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var path string = filepath.Join(t.TempDir(), filepath.Base(fixture))
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestIndexDb(t *testing.T) {
+	vaultData, err := avdu.ReadVaultFile("test/data/aegis_plain_grouped_v3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var index avdu.Index = avdu.Index{Entries: avdu.Metadata(vaultData)}
+	var db vault.Db = index.Db()
+
+	if len(db.Entries) != len(vaultData.Db.Entries) {
+		t.Fatalf("Db() = %v entries; want %v", len(db.Entries), len(vaultData.Db.Entries))
+	}
+
+	for i, entry := range db.Entries {
+		var want vault.Entry = vaultData.Db.Entries[i]
+
+		if entry.Uuid != want.Uuid || entry.Type != want.Type || entry.Info.Digits != want.Info.Digits || entry.Info.Secret != "" {
+			t.Fatalf("[%v] Db() entry = %v; want %v without secrets", i, entry, want.Redacted())
+		}
+
+		if !reflect.DeepEqual(db.EntryGroupNames(entry), vaultData.Db.EntryGroupNames(want)) {
+			t.Fatalf("[%v] Db() groups = %v; want %v", i, db.EntryGroupNames(entry), vaultData.Db.EntryGroupNames(want))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package avdu
+
+import "github.com/sammy-t/avdu/vault"
+
+// lockedBuffer holds key material in memory that's locked from being
+// swapped to disk where the platform allows it.
+type lockedBuffer struct {
+	data   []byte
+	locked bool // Whether the memory was successfully locked
+}
+
+// newLockedBuffer copies the key into a new locked buffer and wipes the key.
+func newLockedBuffer(key []byte) (*lockedBuffer, error) {
+	data, locked, err := allocLocked(len(key))
+	if err != nil {
+		return nil, err
+	}
+
+	copy(data, key)
+	vault.Wipe(key)
+
+	return &lockedBuffer{data: data, locked: locked}, nil
+}
+
+// bytes returns the buffer's contents. The slice must not be kept after destroy.
+func (b *lockedBuffer) bytes() []byte {
+	return b.data
+}
+
+// destroy wipes and releases the buffer.
+func (b *lockedBuffer) destroy() {
+	if b.data == nil {
+		return
+	}
+
+	vault.Wipe(b.data)
+	freeLocked(b.data, b.locked)
+
+	b.data = nil
+}
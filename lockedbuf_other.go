@@ -0,0 +1,13 @@
+//go:build !unix
+
+package avdu
+
+// allocLocked is a helper to allocate memory for key material.
+//
+// Memory locking isn't supported on this platform so the memory is only wiped after use.
+func allocLocked(size int) ([]byte, bool, error) {
+	return make([]byte, size), false, nil
+}
+
+// freeLocked is a helper to release memory from allocLocked.
+func freeLocked(data []byte, locked bool) {}
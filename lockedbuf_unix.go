@@ -0,0 +1,26 @@
+//go:build unix
+
+package avdu
+
+import "golang.org/x/sys/unix"
+
+// allocLocked is a helper to allocate memory outside the Go heap and lock it.
+//
+// The memory is still returned if it can't be locked, ex. when RLIMIT_MEMLOCK is too low.
+func allocLocked(size int) ([]byte, bool, error) {
+	data, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, unix.Mlock(data) == nil, nil
+}
+
+// freeLocked is a helper to unlock and unmap memory from allocLocked.
+func freeLocked(data []byte, locked bool) {
+	if locked {
+		_ = unix.Munlock(data)
+	}
+
+	_ = unix.Munmap(data)
+}
@@ -0,0 +1,67 @@
+package avdu
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+// EntryMetadata identifies and describes an entry without any of its secret data.
+type EntryMetadata struct {
+	Uuid     string   `json:"uuid"`
+	Issuer   string   `json:"issuer"`
+	Name     string   `json:"name"`
+	Groups   []string `json:"groups,omitzero"` // Group names rather than uuids
+	Type     string   `json:"type,omitempty"`
+	Algo     string   `json:"algo,omitempty"`
+	Digits   int      `json:"digits,omitempty"`
+	Period   int      `json:"period,omitempty"`
+	Favorite bool     `json:"favorite,omitempty"`
+}
+
+// Metadata returns the metadata of each entry in the vault's database.
+//
+// Group memberships are resolved to names for every database version.
+func Metadata(vaultData *vault.Vault) []EntryMetadata {
+	var entries []EntryMetadata = make([]EntryMetadata, 0, len(vaultData.Db.Entries))
+
+	for _, entry := range vaultData.Db.Entries {
+		entries = append(entries, EntryMetadata{
+			Uuid:     entry.Uuid,
+			Issuer:   entry.Issuer,
+			Name:     entry.Name,
+			Groups:   vaultData.Db.EntryGroupNames(entry),
+			Type:     entry.Type,
+			Algo:     entry.Info.Algo,
+			Digits:   entry.Info.Digits,
+			Period:   entry.Info.Period,
+			Favorite: entry.Favorite,
+		})
+	}
+
+	return entries
+}
+
+// Matches reports whether the entry matches the filter and group.
+//
+// The filter matches a case-insensitive substring of the issuer or name
+// and the group matches a group name regardless of case.
+// An empty filter or group matches every entry.
+func (m EntryMetadata) Matches(filter string, group string) bool {
+	if filter != "" {
+		var lowerFilter string = strings.ToLower(filter)
+
+		if !strings.Contains(strings.ToLower(m.Issuer), lowerFilter) && !strings.Contains(strings.ToLower(m.Name), lowerFilter) {
+			return false
+		}
+	}
+
+	if group == "" {
+		return true
+	}
+
+	return slices.ContainsFunc(m.Groups, func(name string) bool {
+		return strings.EqualFold(name, group)
+	})
+}
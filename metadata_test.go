@@ -0,0 +1,67 @@
+package avdu_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+)
+
+func TestMetadata(t *testing.T) {
+	var fixtures []string = []string{
+		"test/data/aegis_plain_grouped_v2.json",
+		"test/data/aegis_plain_grouped_v3.json",
+	}
+
+	for _, path := range fixtures {
+		vaultData, err := avdu.ReadVaultFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var entries []avdu.EntryMetadata = avdu.Metadata(vaultData)
+
+		if len(entries) != len(vaultData.Db.Entries) {
+			t.Fatalf("[%v] Metadata() = %v entries; want %v", path, len(entries), len(vaultData.Db.Entries))
+		}
+
+		for i, entry := range vaultData.Db.Entries {
+			var meta avdu.EntryMetadata = entries[i]
+
+			if meta.Uuid != entry.Uuid || meta.Issuer != entry.Issuer || meta.Name != entry.Name {
+				t.Fatalf("[%v][%v] Metadata() = %v; want entry %v", path, i, meta, entry.Uuid)
+			}
+
+			if !slices.Equal(meta.Groups, vaultData.Db.EntryGroupNames(entry)) {
+				t.Fatalf("[%v][%v] Metadata() groups = %v; want %v", path, i, meta.Groups, vaultData.Db.EntryGroupNames(entry))
+			}
+		}
+	}
+}
+
+func TestEntryMetadataMatches(t *testing.T) {
+	var meta avdu.EntryMetadata = avdu.EntryMetadata{Issuer: "Air Canada", Name: "Benjamin", Groups: []string{"Travel", "Work"}}
+
+	type matchTest struct {
+		filter string
+		group  string
+		want   bool
+	}
+
+	var vectors []matchTest = []matchTest{
+		{"", "", true},
+		{"canada", "", true},
+		{"JAMIN", "", true},
+		{"deno", "", false},
+		{"", "work", true},
+		{"", "Home", false},
+		{"air", "travel", true},
+		{"air", "home", false},
+	}
+
+	for i, vector := range vectors {
+		if got := meta.Matches(vector.filter, vector.group); got != vector.want {
+			t.Fatalf("[%v] Matches(%q, %q) = %v; want %v", i, vector.filter, vector.group, got, vector.want)
+		}
+	}
+}
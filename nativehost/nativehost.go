@@ -0,0 +1,317 @@
+// Package nativehost implements a WebExtension native messaging host
+// that lets a browser extension fill codes from an Aegis Authenticator vault.
+//
+// Messages are json prefixed with their length as a 32-bit unsigned integer
+// in native byte order. Entries are listed for a site without unlocking
+// the vault when it's plaintext or has a current plaintext index,
+// but codes are only returned after the vault is unlocked.
+package nativehost
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+)
+
+// maxMessageLen is the largest message accepted from or sent to the browser,
+// matching the limit browsers place on messages from native hosts.
+const maxMessageLen int = 1024 * 1024
+
+// The request types the host handles.
+const (
+	RequestStatus string = "status" // Reports whether the vault is locked
+	RequestUnlock string = "unlock" // Unlocks the vault with the password
+	RequestLock   string = "lock"   // Locks the vault, wiping its master key
+	RequestMatch  string = "match"  // Lists the entries matching the origin
+	RequestCode   string = "code"   // Generates the code of an entry matching the origin
+)
+
+// ErrLocked is returned when codes are requested before the vault is unlocked.
+var ErrLocked = errors.New("the vault is locked")
+
+// Request is a message from the browser extension.
+type Request struct {
+	Id       any    `json:"id,omitempty"` // Echoed in the response so requests can be matched to responses
+	Type     string `json:"type"`
+	Password string `json:"password,omitempty"`
+	Origin   string `json:"origin,omitempty"`
+	Uuid     string `json:"uuid,omitempty"`
+}
+
+// Response is a message to the browser extension.
+type Response struct {
+	Id      any     `json:"id,omitempty"`
+	Type    string  `json:"type"`
+	Error   string  `json:"error,omitempty"`
+	Locked  bool    `json:"locked"`
+	Entries []Match `json:"entries,omitzero"`
+	Code    string  `json:"code,omitempty"`
+	Ttn     int64   `json:"ttn,omitempty"` // The millis until the code refreshes
+}
+
+// Match is an entry that fills codes on the requested origin.
+type Match struct {
+	Uuid   string `json:"uuid"`
+	Issuer string `json:"issuer"`
+	Name   string `json:"name"`
+}
+
+// Host answers requests for the vault file at its path.
+// A Host is safe for concurrent use by multiple goroutines.
+type Host struct {
+	mu sync.Mutex
+
+	path    string
+	rules   Rules
+	opts    vault.KeyOptions
+	session *avdu.Session // The unlocked vault, nil while locked
+}
+
+// NewHost creates a locked host for the vault file at the path.
+func NewHost(vaultPath string, rules Rules, opts vault.KeyOptions) *Host {
+	return &Host{path: vaultPath, rules: rules, opts: opts}
+}
+
+// ReadMessage reads a length-prefixed message from the reader.
+// io.EOF is returned when the browser closes the connection between messages.
+func ReadMessage(r io.Reader) ([]byte, error) {
+	var length uint32
+
+	if err := binary.Read(r, binary.NativeEndian, &length); err != nil {
+		return nil, err
+	}
+
+	if int64(length) > int64(maxMessageLen) {
+		return nil, fmt.Errorf("message of %v bytes is too long", length)
+	}
+
+	var data []byte = make([]byte, length)
+
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("cannot read message: %w", err)
+	}
+
+	return data, nil
+}
+
+// WriteMessage writes the message to the writer as length-prefixed json.
+func WriteMessage(w io.Writer, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if len(data) > maxMessageLen {
+		return fmt.Errorf("message of %v bytes is too long", len(data))
+	}
+
+	if err := binary.Write(w, binary.NativeEndian, uint32(len(data))); err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// Serve answers the requests read from the reader until it's closed,
+// writing a response for each request.
+//
+// Invalid requests are answered with an error rather than stopping the host.
+func (h *Host) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	for {
+		data, err := ReadMessage(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var req Request
+		var resp Response
+
+		if err := json.Unmarshal(data, &req); err != nil {
+			resp = Response{Error: fmt.Sprintf("invalid request: %v", err), Locked: h.Locked()}
+		} else {
+			resp = h.Handle(ctx, req)
+		}
+
+		if err := WriteMessage(w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// Handle answers the request.
+func (h *Host) Handle(ctx context.Context, req Request) Response {
+	var resp Response = Response{Id: req.Id, Type: req.Type}
+	var err error
+
+	switch req.Type {
+	case RequestStatus:
+	case RequestUnlock:
+		err = h.unlock(ctx, req.Password)
+	case RequestLock:
+		h.Lock()
+	case RequestMatch:
+		resp.Entries, err = h.match(req.Origin)
+	case RequestCode:
+		resp.Code, resp.Ttn, err = h.code(req.Origin, req.Uuid)
+	default:
+		err = fmt.Errorf("unknown request type %q", req.Type)
+	}
+
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	resp.Locked = h.Locked()
+
+	return resp
+}
+
+// Locked reports whether the vault is locked.
+func (h *Host) Locked() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.session == nil
+}
+
+// Lock wipes the vault's master key and decrypted database.
+func (h *Host) Lock() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.session != nil {
+		h.session.Close()
+		h.session = nil
+	}
+}
+
+// unlock is a helper to open a session for the vault with the password.
+// Plaintext vaults are unlocked without a password.
+func (h *Host) unlock(ctx context.Context, pwd string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.session != nil {
+		return nil
+	}
+
+	session, err := avdu.OpenSession(ctx, h.path, pwd, h.opts)
+	if err != nil {
+		return err
+	}
+
+	h.session = session
+
+	return nil
+}
+
+// match is a helper to list the entries matching the origin.
+func (h *Host) match(origin string) ([]Match, error) {
+	entries, err := h.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match = []Match{}
+
+	for _, entry := range entries {
+		if h.rules.Match(entry, origin) {
+			matches = append(matches, Match{Uuid: entry.Uuid, Issuer: entry.Issuer, Name: entry.Name})
+		}
+	}
+
+	return matches, nil
+}
+
+// code is a helper to generate the code of the entry with the uuid
+// if it matches the origin, along with the millis until it refreshes.
+func (h *Host) code(origin string, uuid string) (string, int64, error) {
+	h.mu.Lock()
+	var session *avdu.Session = h.session
+	h.mu.Unlock()
+
+	if session == nil {
+		return "", 0, ErrLocked
+	}
+
+	db, err := session.Db()
+	if err != nil {
+		return "", 0, ErrLocked
+	}
+
+	var entries []avdu.EntryMetadata = avdu.Metadata(&vault.Vault{Db: db})
+
+	for i, entry := range db.Entries {
+		if entry.Uuid != uuid {
+			continue
+		}
+
+		// Only fill codes on the sites the entry is for
+		if !h.rules.Match(entries[i], origin) {
+			return "", 0, fmt.Errorf("entry %v doesn't match %q", uuid, origin)
+		}
+
+		// HOTP codes depend on a counter that avdu doesn't update
+		if entry.Type == "hotp" {
+			return "", 0, fmt.Errorf("entry %v is an unsupported hotp entry", uuid)
+		}
+
+		pass, err := avdu.GetOTP(entry)
+		if err != nil {
+			return "", 0, err
+		}
+
+		return pass.String(), avdu.NextRefresh([]vault.Entry{entry}), nil
+	}
+
+	return "", 0, fmt.Errorf("entry %v not found", uuid)
+}
+
+// metadata is a helper to get the entries' metadata from the unlocked vault,
+// or without unlocking it from a plaintext vault or its plaintext index.
+func (h *Host) metadata() ([]avdu.EntryMetadata, error) {
+	h.mu.Lock()
+	var session *avdu.Session = h.session
+	h.mu.Unlock()
+
+	if session != nil {
+		db, err := session.Db()
+		if err == nil {
+			return avdu.Metadata(&vault.Vault{Db: db}), nil
+		}
+	}
+
+	file, err := avdu.Open(h.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !file.Encrypted() {
+		vaultData, err := file.Vault()
+		if err != nil {
+			return nil, err
+		}
+
+		return avdu.Metadata(vaultData), nil
+	}
+
+	index, err := avdu.ReadVaultIndex(h.path, nil)
+	if err != nil {
+		return nil, ErrLocked
+	}
+
+	return index.Entries, nil
+}
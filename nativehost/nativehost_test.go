@@ -0,0 +1,169 @@
+package nativehost_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/nativehost"
+	"github.com/sammy-t/avdu/vault"
+)
+
+const (
+	airbnbUuid string = "3deaff2e-f181-4837-80e1-fdf0c54e9363"
+	wweUuid    string = "b25f8815-007f-40f7-a700-ce058ac05435"
+)
+
+var testRules nativehost.Rules = nativehost.Rules{
+	Rules: []nativehost.Rule{
+		{Uuid: airbnbUuid, Sites: []string{"airbnb.com"}},
+		{Uuid: wweUuid, Sites: []string{"https://wrestling.example/login"}},
+	},
+}
+
+var vectorsRules = []struct {
+	entry  avdu.EntryMetadata
+	origin string
+	want   bool
+}{
+	// Entries with a rule match its sites and their subdomains
+	{avdu.EntryMetadata{Uuid: airbnbUuid, Issuer: "Airbnb"}, "https://www.airbnb.com", true},
+	{avdu.EntryMetadata{Uuid: airbnbUuid, Issuer: "Airbnb"}, "https://airbnb.com:443", true},
+	{avdu.EntryMetadata{Uuid: airbnbUuid, Issuer: "Airbnb"}, "https://airbnb.evil.example", false},
+	{avdu.EntryMetadata{Uuid: airbnbUuid, Issuer: "Airbnb"}, "https://airbnb.xyz", false},
+	{avdu.EntryMetadata{Uuid: airbnbUuid, Issuer: "Airbnb"}, "http://www.airbnb.com", false},
+	{avdu.EntryMetadata{Uuid: wweUuid, Issuer: "WWE"}, "https://shop.wrestling.example", true},
+	{avdu.EntryMetadata{Uuid: wweUuid, Issuer: "wwe.com"}, "https://wwe.com", false},
+
+	// Entries without a rule only match the registrable domain their issuer names
+	{avdu.EntryMetadata{Issuer: "GitHub"}, "https://github.com", false},
+	{avdu.EntryMetadata{Issuer: "github.com"}, "https://github.com", true},
+	{avdu.EntryMetadata{Issuer: "GitHub.com"}, "https://gist.github.com", true},
+	{avdu.EntryMetadata{Issuer: "github.com"}, "https://github.xyz", false},
+	{avdu.EntryMetadata{Issuer: "github.com"}, "https://github.com.evil.example", false},
+	{avdu.EntryMetadata{Issuer: "github.com"}, "http://github.com", false},
+	{avdu.EntryMetadata{Issuer: "paypal.co.uk"}, "https://www.paypal.co.uk", true},
+	{avdu.EntryMetadata{Issuer: "paypal.co.uk"}, "https://login.paypal.phish.co.uk", false},
+	{avdu.EntryMetadata{Issuer: "co.uk"}, "https://example.co.uk", false},
+	{avdu.EntryMetadata{Issuer: "Air Canada"}, "https://aircanada.com", false},
+	{avdu.EntryMetadata{Issuer: ""}, "https://example.com", false},
+	{avdu.EntryMetadata{Issuer: "deno.land"}, "deno.land", false},
+	{avdu.EntryMetadata{Issuer: "deno.land"}, "not a url", false},
+	{avdu.EntryMetadata{Issuer: "127.0.0.1"}, "https://127.0.0.1", false},
+}
+
+func TestRulesMatch(t *testing.T) {
+	for i, vector := range vectorsRules {
+		if got := testRules.Match(vector.entry, vector.origin); got != vector.want {
+			t.Fatalf("[%v] Match(%v, %q) = %v; want %v", i, vector.entry.Issuer, vector.origin, got, vector.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	var buffer bytes.Buffer
+
+	if err := nativehost.WriteMessage(&buffer, nativehost.Request{Type: nativehost.RequestStatus}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := nativehost.ReadMessage(&buffer)
+	if err != nil || string(data) != `{"type":"status"}` {
+		t.Fatalf("ReadMessage() = %s, %v; want the written message", data, err)
+	}
+
+	// A length larger than the limit is rejected before reading the message
+	if _, err := nativehost.ReadMessage(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff})); err == nil {
+		t.Fatal("ReadMessage() with a 4 GiB length = nil; want error")
+	}
+}
+
+func TestServe(t *testing.T) {
+	data, err := os.ReadFile("../test/data/aegis_encrypted.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var path string = filepath.Join(t.TempDir(), "aegis_encrypted.json")
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type serveTest struct {
+		req     nativehost.Request
+		locked  bool
+		failed  bool
+		entries []string // The uuids of the matching entries
+	}
+
+	var vectors []serveTest = []serveTest{
+		{nativehost.Request{Type: nativehost.RequestStatus}, true, false, nil},
+		{nativehost.Request{Type: nativehost.RequestMatch, Origin: "https://airbnb.com"}, true, true, nil},
+		{nativehost.Request{Type: nativehost.RequestCode, Origin: "https://airbnb.com", Uuid: airbnbUuid}, true, true, nil},
+		{nativehost.Request{Type: nativehost.RequestUnlock, Password: "wrong"}, true, true, nil},
+		{nativehost.Request{Type: nativehost.RequestUnlock, Password: "test"}, false, false, nil},
+		{nativehost.Request{Type: nativehost.RequestMatch, Origin: "https://www.airbnb.com"}, false, false, []string{airbnbUuid}},
+		{nativehost.Request{Type: nativehost.RequestMatch, Origin: "https://wrestling.example"}, false, false, []string{wweUuid}},
+		{nativehost.Request{Type: nativehost.RequestCode, Origin: "https://www.airbnb.com", Uuid: airbnbUuid}, false, false, nil},
+		{nativehost.Request{Type: nativehost.RequestCode, Origin: "https://deno.land", Uuid: airbnbUuid}, false, true, nil},
+		{nativehost.Request{Type: "unknown"}, false, true, nil},
+		{nativehost.Request{Type: nativehost.RequestLock}, true, false, nil},
+		{nativehost.Request{Type: nativehost.RequestCode, Origin: "https://www.airbnb.com", Uuid: airbnbUuid}, true, true, nil},
+	}
+
+	var stdin, stdout bytes.Buffer
+
+	for i, vector := range vectors {
+		vector.req.Id = i
+
+		if err := nativehost.WriteMessage(&stdin, vector.req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var host *nativehost.Host = nativehost.NewHost(path, testRules, vault.KeyOptions{})
+
+	defer host.Lock()
+
+	if err := host.Serve(context.Background(), &stdin, &stdout); err != nil {
+		t.Fatalf("Serve() = %v; want nil", err)
+	}
+
+	for i, vector := range vectors {
+		data, err := nativehost.ReadMessage(&stdout)
+		if err != nil {
+			t.Fatalf("[%v] ReadMessage() = %v; want nil", i, err)
+		}
+
+		var resp nativehost.Response
+
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.Id != float64(i) || resp.Locked != vector.locked || (resp.Error != "") != vector.failed {
+			t.Fatalf("[%v] %v response = %s; want locked %v, failed %v", i, vector.req.Type, data, vector.locked, vector.failed)
+		}
+
+		if vector.req.Type == nativehost.RequestCode && !vector.failed && (resp.Code == "" || resp.Ttn <= 0) {
+			t.Fatalf("[%v] code response = %s; want a code and ttn", i, data)
+		}
+
+		if vector.entries == nil {
+			continue
+		}
+
+		if len(resp.Entries) != len(vector.entries) || resp.Entries[0].Uuid != vector.entries[0] {
+			t.Fatalf("[%v] match response = %v; want %v", i, resp.Entries, vector.entries)
+		}
+	}
+
+	if stdout.Len() != 0 {
+		t.Fatalf("Serve() wrote %v extra bytes; want one response per request", stdout.Len())
+	}
+}
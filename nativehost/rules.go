@@ -0,0 +1,100 @@
+package nativehost
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/sammy-t/avdu"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Rules assign the sites each entry fills codes on.
+//
+// Entries without a rule only match if their issuer is a domain, like "github.com",
+// and then only sites with the same registrable domain. Only https origins match.
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule lists the sites an entry matches, replacing matching by issuer.
+//
+// Each site is a host or URL and also matches the host's subdomains.
+type Rule struct {
+	Uuid  string   `json:"uuid"`
+	Sites []string `json:"sites"`
+}
+
+// ReadRules reads the rules file at the path.
+func ReadRules(filePath string) (Rules, error) {
+	var rules Rules
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return rules, err
+	}
+
+	err = json.Unmarshal(data, &rules)
+
+	return rules, err
+}
+
+// Match reports whether the entry should fill codes on the https origin.
+func (r Rules) Match(entry avdu.EntryMetadata, origin string) bool {
+	// Codes are never filled on pages an attacker on the network could alter
+	parsed, err := url.Parse(strings.TrimSpace(origin))
+	if err != nil || parsed.Scheme != "https" {
+		return false
+	}
+
+	var host string = strings.ToLower(parsed.Hostname())
+
+	if host == "" {
+		return false
+	}
+
+	for _, rule := range r.Rules {
+		if rule.Uuid != entry.Uuid {
+			continue
+		}
+
+		for _, site := range rule.Sites {
+			var siteHost string = originHost(site)
+
+			if siteHost != "" && (host == siteHost || strings.HasSuffix(host, "."+siteHost)) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// Issuers like "GitHub" name no domain, so matching them against part of the host
+	// would also fill codes on github.xyz or github.evil.example. The whole registrable
+	// domain is compared instead, which only the issuer's owner controls.
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return false
+	}
+
+	issuerDomain, err := publicsuffix.EffectiveTLDPlusOne(originHost(entry.Issuer))
+
+	return err == nil && domain == issuerDomain
+}
+
+// originHost is a helper to get the lowercase host of an origin, URL, or bare host.
+func originHost(origin string) string {
+	origin = strings.TrimSpace(origin)
+
+	if !strings.Contains(origin, "://") {
+		origin = "https://" + origin
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(parsed.Hostname())
+}
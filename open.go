@@ -0,0 +1,127 @@
+package avdu
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+// ErrPasswordRequired is returned when an encrypted vault is used before it's unlocked.
+var ErrPasswordRequired = errors.New("a password is required to decrypt the vault")
+
+// VaultFile is an opened plaintext or encrypted vault.
+//
+// Encrypted vaults must be unlocked before their contents can be used.
+type VaultFile struct {
+	plain     *vault.Vault
+	enc       *vault.VaultEncrypted
+	masterKey []byte
+}
+
+// Open reads the vault file at the path and detects whether it's encrypted
+// from the type of its db field and its header slots.
+func Open(filePath string) (*VaultFile, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return OpenReader(file)
+}
+
+// OpenReader reads the vault from the reader and detects whether it's encrypted
+// from the type of its db field and its header slots.
+func OpenReader(r io.Reader) (*VaultFile, error) {
+	vaultData, vaultDataEnc, err := ReadAnyVault(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if vaultDataEnc != nil && len(vaultDataEnc.Header.Slots) == 0 {
+		return nil, errors.New("encrypted vault has no slots to unlock it with")
+	}
+
+	return &VaultFile{plain: vaultData, enc: vaultDataEnc}, nil
+}
+
+// Encrypted reports whether the vault's database is encrypted.
+func (f *VaultFile) Encrypted() bool {
+	return f.enc != nil
+}
+
+// Locked reports whether the vault is encrypted and hasn't been unlocked.
+func (f *VaultFile) Locked() bool {
+	return f.plain == nil
+}
+
+// EncryptedVault returns the encrypted vault, or nil if the vault is plaintext.
+func (f *VaultFile) EncryptedVault() *vault.VaultEncrypted {
+	return f.enc
+}
+
+// Vault returns the plaintext vault.
+//
+// ErrPasswordRequired is returned if the vault is encrypted and hasn't been unlocked.
+func (f *VaultFile) Vault() (*vault.Vault, error) {
+	if f.plain == nil {
+		return nil, ErrPasswordRequired
+	}
+
+	return f.plain, nil
+}
+
+// Unlock decrypts the vault with the password and returns the plaintext vault.
+//
+// Plaintext vaults are returned as is, ignoring the password.
+func (f *VaultFile) Unlock(pwd string) (*vault.Vault, error) {
+	return f.UnlockContext(context.Background(), pwd, vault.KeyOptions{Concurrency: 1})
+}
+
+// UnlockContext decrypts the vault with the password, trying the password slots
+// in parallel as configured by the options, and returns the plaintext vault.
+//
+// The context's cancellation stops the search for the master key.
+// Plaintext vaults are returned as is, ignoring the password.
+func (f *VaultFile) UnlockContext(ctx context.Context, pwd string, opts vault.KeyOptions) (*vault.Vault, error) {
+	if f.plain != nil {
+		return f.plain, nil
+	}
+
+	if pwd == "" {
+		return nil, ErrPasswordRequired
+	}
+
+	var pwdBytes []byte = []byte(pwd)
+	defer vault.Wipe(pwdBytes)
+
+	masterKey, err := f.enc.FindMasterKeyContext(ctx, pwdBytes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.UnlockWithKey(masterKey)
+}
+
+// UnlockWithKey decrypts the vault with the master key and returns the plaintext vault.
+//
+// Plaintext vaults are returned as is, ignoring the master key.
+func (f *VaultFile) UnlockWithKey(masterKey []byte) (*vault.Vault, error) {
+	if f.plain != nil {
+		return f.plain, nil
+	}
+
+	vaultData, err := f.enc.DecryptVault(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	f.plain = vaultData
+	f.masterKey = masterKey
+
+	return vaultData, nil
+}
@@ -0,0 +1,39 @@
+package avdu_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+)
+
+func TestOpen(t *testing.T) {
+	var vectors = []struct {
+		path      string
+		encrypted bool
+	}{
+		{"test/data/aegis_plain.json", false},
+		{"test/data/aegis_plain_grouped_v3.json", false},
+		{"test/data/aegis_encrypted.json", true},
+	}
+
+	for i, vector := range vectors {
+		file, err := avdu.Open(vector.path)
+		if err != nil || file.Encrypted() != vector.encrypted || file.Locked() != vector.encrypted {
+			t.Fatalf("[%v] Open(%v) = %v, %v; want encrypted and locked %v", i, vector.path, file, err, vector.encrypted)
+		}
+
+		if _, err := file.Vault(); vector.encrypted != errors.Is(err, avdu.ErrPasswordRequired) {
+			t.Fatalf("[%v] Vault() error = %v; want ErrPasswordRequired %v", i, err, vector.encrypted)
+		}
+
+		if _, err := file.Unlock("wrong"); vector.encrypted == (err == nil) {
+			t.Fatalf("[%v] Unlock(wrong) error = %v; want error %v", i, err, vector.encrypted)
+		}
+
+		vaultData, err := file.Unlock("test")
+		if err != nil || len(vaultData.Db.Entries) == 0 || file.Locked() {
+			t.Fatalf("[%v] Unlock(test) = %v, %v; want an unlocked vault with entries", i, vaultData, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package avdu
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// PruneOptions configures which vault files are kept when pruning a directory.
+//
+// The most recent vault file is always kept.
+type PruneOptions struct {
+	KeepLast    int // The number of most recent vault files to keep
+	KeepDaily   int // The number of days to keep the most recent vault file for
+	KeepWeekly  int // The number of weeks to keep the most recent vault file for
+	KeepMonthly int // The number of months to keep the most recent vault file for
+
+	// Dedupe removes vault files whose database matches a more recent vault file.
+	Dedupe bool
+
+	// Password is used to decrypt encrypted vault files, which is required
+	// when deduplicating. Without it, encrypted vault files are only checked
+	// to parse, not to decrypt.
+	Password string
+
+	// AllowUnparsed allows removing vault files whose file name timestamp
+	// or content can't be parsed or decrypted. These files are kept otherwise.
+	AllowUnparsed bool
+}
+
+// PruneDecision describes whether a vault file is kept and why.
+type PruneDecision struct {
+	Candidate VaultCandidate
+	Keep      bool
+	Reason    string
+}
+
+// PrunePlan lists the decision for each vault file in a directory
+// ordered from newest to oldest.
+type PrunePlan []PruneDecision
+
+// PlanPrune decides which vault files in the directory to keep
+// without removing any files.
+func PlanPrune(vaultDir string, opts PruneOptions) (PrunePlan, error) {
+	candidates, err := ListVaultCandidates(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan PrunePlan = make(PrunePlan, len(candidates))
+
+	for i, candidate := range candidates {
+		plan[i] = PruneDecision{Candidate: candidate}
+	}
+
+	if len(plan) == 0 {
+		return plan, nil
+	}
+
+	keep(&plan[0], "newest")
+
+	for i := range plan {
+		if plan[i].Candidate.Time.IsZero() && !opts.AllowUnparsed {
+			keep(&plan[i], "unparsed file name")
+		}
+	}
+
+	// Every file's content is read so corrupt files are never removed as expired
+	if err := readContents(plan, opts); err != nil {
+		return nil, err
+	}
+
+	var daily, weekly, monthly map[string]bool = make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	var last int
+
+	for i := range plan {
+		var decision *PruneDecision = &plan[i]
+
+		if decision.Reason != "" && !decision.Keep {
+			continue // Duplicates don't count toward retention
+		}
+
+		var t time.Time = decision.Candidate.Timestamp()
+
+		year, week := t.ISOWeek()
+
+		if last < opts.KeepLast {
+			keep(decision, "last")
+			last++
+		}
+
+		if bucket := t.Format("2006-01-02"); len(daily) < opts.KeepDaily && !daily[bucket] {
+			keep(decision, "daily")
+			daily[bucket] = true
+		}
+
+		if bucket := fmt.Sprintf("%04d-W%02d", year, week); len(weekly) < opts.KeepWeekly && !weekly[bucket] {
+			keep(decision, "weekly")
+			weekly[bucket] = true
+		}
+
+		if bucket := t.Format("2006-01"); len(monthly) < opts.KeepMonthly && !monthly[bucket] {
+			keep(decision, "monthly")
+			monthly[bucket] = true
+		}
+
+		if decision.Reason == "" {
+			decision.Reason = "expired"
+		}
+	}
+
+	return plan, nil
+}
+
+// Removed returns the decisions for the vault files to be removed.
+func (p PrunePlan) Removed() []PruneDecision {
+	var removed []PruneDecision
+
+	for _, decision := range p {
+		if !decision.Keep {
+			removed = append(removed, decision)
+		}
+	}
+
+	return removed
+}
+
+// Apply removes the vault files the plan doesn't keep.
+//
+// If there's an error, the remaining files are still removed
+// and the errors are returned together.
+func (p PrunePlan) Apply() error {
+	var errs []error
+
+	for _, decision := range p.Removed() {
+		if err := os.Remove(decision.Candidate.Path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// keep is a helper to mark the decision as kept, appending the reason.
+func keep(decision *PruneDecision, reason string) {
+	if decision.Keep {
+		decision.Reason += ", " + reason
+		return
+	}
+
+	decision.Keep = true
+	decision.Reason = reason
+}
+
+// readContents is a helper to keep vault files whose content can't be parsed
+// or decrypted, and to mark vault files whose database matches a more recent
+// vault file for removal if deduplicating.
+func readContents(plan PrunePlan, opts PruneOptions) error {
+	var reader *keyCache = &keyCache{pwd: opts.Password}
+	defer reader.wipe()
+
+	var seen map[string]string = make(map[string]string)
+
+	for i := range plan {
+		var decision *PruneDecision = &plan[i]
+
+		file, err := Open(decision.Candidate.Path)
+		if err != nil {
+			if !opts.AllowUnparsed {
+				keep(decision, "unparsed content")
+			}
+
+			continue
+		}
+
+		if file.Encrypted() && opts.Password == "" {
+			if opts.Dedupe {
+				return fmt.Errorf("cannot deduplicate %q: %w", decision.Candidate.Path, ErrPasswordRequired)
+			}
+
+			continue
+		}
+
+		vaultData, err := reader.unlock(file)
+		if err != nil {
+			if !opts.AllowUnparsed {
+				keep(decision, "undecryptable content")
+			}
+
+			continue
+		}
+
+		if !opts.Dedupe {
+			continue
+		}
+
+		hash, err := vaultData.Db.ContentHash()
+		if err != nil {
+			return err
+		}
+
+		if newer, ok := seen[hash]; ok && !decision.Keep {
+			decision.Reason = fmt.Sprintf("duplicate of %v", newer)
+			continue
+		}
+
+		if _, ok := seen[hash]; !ok {
+			seen[hash] = decision.Candidate.Name
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package avdu_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+)
+
+const unparsedName string = "aegis-backup-1.json" // A vault file name without a timestamp
+
+// pruneNames are the vault files the retention tests prune, newest first.
+var pruneNames []string = []string{
+	"aegis-backup-20240625-120000.json", // Week 26
+	"aegis-backup-20240625-080000.json",
+	"aegis-backup-20240624-120000.json",
+	"aegis-backup-20240620-120000.json", // Week 25
+	"aegis-backup-20240610-120000.json", // Week 24
+	"aegis-backup-20240515-120000.json",
+	"aegis-backup-20240401-120000.json",
+	unparsedName,
+}
+
+type vectorPrune struct {
+	opts avdu.PruneOptions
+	kept []string
+}
+
+var vectorsPrune []vectorPrune = []vectorPrune{
+	{avdu.PruneOptions{}, []string{pruneNames[0], unparsedName}},
+	{avdu.PruneOptions{KeepLast: 3}, []string{pruneNames[0], pruneNames[1], pruneNames[2], unparsedName}},
+	{avdu.PruneOptions{KeepDaily: 3}, []string{pruneNames[0], pruneNames[2], pruneNames[3], unparsedName}},
+	{avdu.PruneOptions{KeepWeekly: 2}, []string{pruneNames[0], pruneNames[3], unparsedName}},
+	{avdu.PruneOptions{KeepMonthly: 3}, []string{pruneNames[0], pruneNames[5], pruneNames[6], unparsedName}},
+	{avdu.PruneOptions{KeepLast: 2, KeepMonthly: 2}, []string{pruneNames[0], pruneNames[1], pruneNames[5], unparsedName}},
+	{avdu.PruneOptions{AllowUnparsed: true}, []string{pruneNames[0]}},
+}
+
+func TestPlanPrune(t *testing.T) {
+	for i, vector := range vectorsPrune {
+		var dir string = t.TempDir()
+
+		for _, name := range pruneNames {
+			writeFixture(t, "test/data/aegis_plain.json", filepath.Join(dir, name))
+		}
+
+		plan, err := avdu.PlanPrune(dir, vector.opts)
+		if err != nil {
+			t.Fatalf("[%v] PlanPrune() = %v; want nil", i, err)
+		}
+
+		// Planning is a dry run that doesn't remove any files
+		if got := dirNames(t, dir); len(got) != len(pruneNames) {
+			t.Fatalf("[%v] PlanPrune() left %v; want every file", i, got)
+		}
+
+		if err := plan.Apply(); err != nil {
+			t.Fatalf("[%v] Apply() = %v; want nil", i, err)
+		}
+
+		var want []string = slices.Sorted(slices.Values(vector.kept))
+
+		if got := dirNames(t, dir); !slices.Equal(got, want) {
+			t.Fatalf("[%v] Apply() kept %v; want %v", i, got, want)
+		}
+	}
+}
+
+func TestPlanPruneDedupe(t *testing.T) {
+	var dir string = t.TempDir()
+
+	var fixtures map[string]string = map[string]string{
+		"aegis-backup-20240625-000003.json": "test/data/aegis_plain_grouped_v3.json",
+		"aegis-backup-20240625-000002.json": "test/data/aegis_plain.json",
+		"aegis-backup-20240625-000001.json": "test/data/aegis_plain.json",
+		"aegis-backup-20240624-000002.json": "test/data/aegis_encrypted.json",
+		"aegis-backup-20240624-000001.json": "test/data/aegis_encrypted.json",
+	}
+
+	for name, fixture := range fixtures {
+		writeFixture(t, fixture, filepath.Join(dir, name))
+	}
+
+	// The same database written with absent rather than null members is still a duplicate
+	var reshapedName string = "aegis-backup-20240625-000000.json"
+
+	data, err := os.ReadFile("test/data/aegis_plain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data = regexp.MustCompile(`\s*"icon": null,`).ReplaceAll(data, nil)
+
+	if err := os.WriteFile(filepath.Join(dir, reshapedName), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files whose content can't be read are kept rather than compared
+	var corruptName string = "aegis-backup-20240101-000000.json"
+
+	if err := os.WriteFile(filepath.Join(dir, corruptName), []byte("not a vault"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Encrypted vault files can't be compared without the password
+	if _, err := avdu.PlanPrune(dir, avdu.PruneOptions{KeepLast: 10, Dedupe: true}); !errors.Is(err, avdu.ErrPasswordRequired) {
+		t.Fatalf("PlanPrune() without a password = %v; want %v", err, avdu.ErrPasswordRequired)
+	}
+
+	plan, err := avdu.PlanPrune(dir, avdu.PruneOptions{KeepLast: 10, Dedupe: true, Password: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var removed map[string]bool = make(map[string]bool)
+
+	for _, decision := range plan.Removed() {
+		if !strings.HasPrefix(decision.Reason, "duplicate of ") {
+			t.Fatalf("PlanPrune() removes %v for %q; want only duplicates", decision.Candidate.Name, decision.Reason)
+		}
+
+		removed[decision.Candidate.Name] = true
+	}
+
+	// The plaintext and encrypted copies are duplicates of a newer file with the same database
+	for _, name := range []string{"aegis-backup-20240625-000001.json", reshapedName, "aegis-backup-20240624-000001.json"} {
+		if !removed[name] {
+			t.Fatalf("PlanPrune() keeps %v; want it removed as a duplicate", name)
+		}
+	}
+
+	if removed["aegis-backup-20240625-000003.json"] || removed["aegis-backup-20240625-000002.json"] || removed[corruptName] {
+		t.Fatalf("PlanPrune() removes %v; want the newest copy of each database kept", removed)
+	}
+
+	if got := dirNames(t, dir); len(got) != len(fixtures)+2 {
+		t.Fatalf("PlanPrune() left %v; want every file", got)
+	}
+}
+
+func TestPlanPruneContent(t *testing.T) {
+	var dir string = t.TempDir()
+
+	writeFixture(t, "test/data/aegis_plain.json", filepath.Join(dir, "aegis-backup-20240625-000003.json"))
+	writeFixture(t, "test/data/aegis_encrypted.json", filepath.Join(dir, "aegis-backup-20240625-000002.json"))
+
+	// A corrupt file with a valid name isn't removed as expired
+	var corruptName string = "aegis-backup-20240625-000001.json"
+
+	if err := os.WriteFile(filepath.Join(dir, corruptName), []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type contentTest struct {
+		opts    avdu.PruneOptions
+		removed []string
+	}
+
+	var vectors []contentTest = []contentTest{
+		// Without a password, encrypted files are only checked to parse
+		{avdu.PruneOptions{}, []string{"aegis-backup-20240625-000002.json"}},
+		{avdu.PruneOptions{Password: "test"}, []string{"aegis-backup-20240625-000002.json"}},
+		{avdu.PruneOptions{Password: "wrong"}, nil},
+		{avdu.PruneOptions{Password: "wrong", AllowUnparsed: true}, []string{"aegis-backup-20240625-000002.json", corruptName}},
+	}
+
+	for i, vector := range vectors {
+		plan, err := avdu.PlanPrune(dir, vector.opts)
+		if err != nil {
+			t.Fatalf("[%v] PlanPrune() = %v; want nil", i, err)
+		}
+
+		var removed []string
+
+		for _, decision := range plan.Removed() {
+			removed = append(removed, decision.Candidate.Name)
+		}
+
+		if !slices.Equal(removed, vector.removed) {
+			t.Fatalf("[%v] PlanPrune() removes %v; want %v", i, removed, vector.removed)
+		}
+
+		for _, decision := range plan {
+			if decision.Candidate.Name == corruptName && decision.Keep && decision.Reason != "unparsed content" {
+				t.Fatalf("[%v] PlanPrune() keeps %v for %q; want unparsed content", i, corruptName, decision.Reason)
+			}
+		}
+	}
+}
+
+// writeFixture is a helper to copy the fixture to the path.
+func writeFixture(t *testing.T, fixture string, filePath string) {
+	t.Helper()
+
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// dirNames is a helper to list the sorted file names in the directory.
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+
+	return names
+}
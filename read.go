@@ -0,0 +1,167 @@
+package avdu
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+// ReadVault parses the json from the reader
+// and returns a plaintext vault.
+func ReadVault(r io.Reader) (*vault.Vault, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseVault(data)
+}
+
+// ReadVaultEnc parses the json from the reader
+// and returns an encrypted vault.
+func ReadVaultEnc(r io.Reader) (*vault.VaultEncrypted, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseVaultEnc(data)
+}
+
+// ReadAnyVault parses the json from the reader and detects whether
+// the vault is plaintext or encrypted.
+//
+// Exactly one of the returned vaults is non-nil when there's no error.
+func ReadAnyVault(r io.Reader) (*vault.Vault, *vault.VaultEncrypted, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encrypted, err := isEncrypted(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if encrypted {
+		vaultDataEnc, err := parseVaultEnc(data)
+		return nil, vaultDataEnc, err
+	}
+
+	vaultData, err := parseVault(data)
+
+	return vaultData, nil, err
+}
+
+// ReadVaultFS parses the json file with the name in the file system
+// and returns a plaintext vault.
+func ReadVaultFS(fsys fs.FS, name string) (*vault.Vault, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseVault(data)
+}
+
+// ReadVaultEncFS parses the json file with the name in the file system
+// and returns an encrypted vault.
+func ReadVaultEncFS(fsys fs.FS, name string) (*vault.VaultEncrypted, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseVaultEnc(data)
+}
+
+// ListVaultCandidatesFS returns the vault files in the file system's directory
+// ordered from newest to oldest by their file name timestamps.
+//
+// The candidates' paths are names in the file system rather than OS paths.
+func ListVaultCandidatesFS(fsys fs.FS, dir string) ([]VaultCandidate, error) {
+	files, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return listCandidates(files, func(name string) string {
+		return path.Join(dir, name)
+	})
+}
+
+// FindVaultPathFS returns the name of the vault in the file system's directory
+// with the newest file name timestamp.
+func FindVaultPathFS(fsys fs.FS, dir string) (string, error) {
+	return FindVaultPathWithFS(fsys, dir, SelectFilenameTime, 0)
+}
+
+// FindVaultPathWithFS returns the name of the vault
+// in the file system's directory chosen by the policy.
+func FindVaultPathWithFS(fsys fs.FS, dir string, policy SelectPolicy, index int) (string, error) {
+	candidates, err := ListVaultCandidatesFS(fsys, dir)
+	if err != nil {
+		return "", err
+	}
+
+	var readFile func(name string) ([]byte, error) = func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	}
+
+	selected, err := selectVault(candidates, policy, index, readFile)
+	if err != nil {
+		return "", err
+	}
+
+	return selected.Path, nil
+}
+
+// parseVault is a helper to parse the json data as a plaintext vault.
+func parseVault(data []byte) (*vault.Vault, error) {
+	var vaultData vault.Vault
+
+	if err := json.Unmarshal(data, &vaultData); err != nil {
+		return nil, err
+	}
+
+	// A simple check to see if the parsed vault is valid
+	if vaultData.Version == 0 {
+		return nil, errors.New("invalid or empty vault")
+	}
+
+	return &vaultData, nil
+}
+
+// parseVaultEnc is a helper to parse the json data as an encrypted vault.
+func parseVaultEnc(data []byte) (*vault.VaultEncrypted, error) {
+	var vaultDataEnc vault.VaultEncrypted
+
+	if err := json.Unmarshal(data, &vaultDataEnc); err != nil {
+		return nil, err
+	}
+
+	// A simple check to see if the parsed vault is valid
+	if vaultDataEnc.Version == 0 {
+		return nil, errors.New("invalid or empty vault")
+	}
+
+	return &vaultDataEnc, nil
+}
+
+// isEncrypted is a helper to check whether the vault's database
+// is an encrypted string rather than a plaintext object.
+func isEncrypted(data []byte) (bool, error) {
+	var vaultData struct {
+		Db json.RawMessage `json:"db"`
+	}
+
+	if err := json.Unmarshal(data, &vaultData); err != nil {
+		return false, err
+	}
+
+	return len(vaultData.Db) > 0 && vaultData.Db[0] == '"', nil
+}
@@ -0,0 +1,66 @@
+package avdu_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sammy-t/avdu"
+)
+
+func TestReadAnyVault(t *testing.T) {
+	var vectors = []struct {
+		path      string
+		encrypted bool
+	}{
+		{"test/data/aegis_plain.json", false},
+		{"test/data/aegis_encrypted.json", true},
+	}
+
+	for i, vector := range vectors {
+		data, err := os.ReadFile(vector.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vaultData, vaultDataEnc, err := avdu.ReadAnyVault(bytes.NewReader(data))
+
+		if err != nil || (vaultData == nil) != vector.encrypted || (vaultDataEnc == nil) == vector.encrypted {
+			t.Fatalf("[%v] ReadAnyVault(%v) = %v, %v, %v; want encrypted %v", i, vector.path, vaultData, vaultDataEnc, err, vector.encrypted)
+		}
+	}
+
+	if _, _, err := avdu.ReadAnyVault(bytes.NewReader([]byte(`{}`))); err == nil {
+		t.Fatalf("ReadAnyVault({}) error = nil; want an error")
+	}
+}
+
+func TestFindVaultPathFS(t *testing.T) {
+	plain, err := os.ReadFile("test/data/aegis_plain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fsys fstest.MapFS = fstest.MapFS{
+		"backups/aegis-backup-20240625-000001.json": {Data: plain},
+		"backups/aegis-backup-20240626-000001.json": {Data: plain},
+		"backups/notes.txt":                         {Data: []byte("not a vault")},
+	}
+
+	name, err := avdu.FindVaultPathFS(fsys, "backups")
+
+	if err != nil || name != "backups/aegis-backup-20240626-000001.json" {
+		t.Fatalf("FindVaultPathFS() = %v, %v; want backups/aegis-backup-20240626-000001.json, nil", name, err)
+	}
+
+	vaultData, err := avdu.ReadVaultFS(fsys, name)
+
+	if err != nil || len(vaultData.Db.Entries) == 0 {
+		t.Fatalf("ReadVaultFS() = %v, %v; want a vault with entries", vaultData, err)
+	}
+
+	if _, err := avdu.ReadVaultEncFS(fsys, name); err == nil {
+		t.Fatalf("ReadVaultEncFS() error = nil; want an error for a plaintext vault")
+	}
+}
@@ -0,0 +1,286 @@
+package avdu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vaultFileRE matches the file names of Aegis vault backups and exports.
+var vaultFileRE = regexp.MustCompile(`^aegis-(backup|export)-(\d+(?:-\d+)*)\.json$`)
+
+// SelectPolicy determines which vault file is chosen from a directory.
+type SelectPolicy string
+
+const (
+	SelectFilenameTime SelectPolicy = "filename" // The newest timestamp and sequence in the file name
+	SelectModTime      SelectPolicy = "mtime"    // The most recently modified file
+	SelectVersion      SelectPolicy = "version"  // The largest vault version, then database version if both are plaintext
+	SelectIndex        SelectPolicy = "index"    // An explicit index into the candidates, newest first
+)
+
+// VaultCandidate describes a vault file found in a directory.
+type VaultCandidate struct {
+	Name    string    // The file name
+	Path    string    // The file path
+	Time    time.Time // The timestamp parsed from the file name, zero if it can't be parsed
+	Seq     []int64   // The numeric groups of the file name following the timestamp
+	ModTime time.Time // The file's last modified time
+}
+
+// Timestamp returns the time parsed from the file name,
+// falling back to the file's last modified time.
+func (c VaultCandidate) Timestamp() time.Time {
+	if c.Time.IsZero() {
+		return c.ModTime
+	}
+
+	return c.Time
+}
+
+// StatVaultCandidate describes the vault file at the path.
+//
+// Files not named like an Aegis backup or export have a zero Time.
+func StatVaultCandidate(filePath string) (VaultCandidate, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return VaultCandidate{}, err
+	}
+
+	var candidate VaultCandidate = VaultCandidate{Name: info.Name()}
+
+	if vaultFileRE.MatchString(info.Name()) {
+		candidate = parseVaultFileName(info.Name())
+	}
+
+	candidate.Path = filePath
+	candidate.ModTime = info.ModTime()
+
+	return candidate, nil
+}
+
+// ParseSelectPolicy returns the policy matching the name.
+func ParseSelectPolicy(name string) (SelectPolicy, error) {
+	var policy SelectPolicy = SelectPolicy(strings.ToLower(name))
+
+	switch policy {
+	case SelectFilenameTime, SelectModTime, SelectVersion, SelectIndex:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported select policy %q", name)
+	}
+}
+
+// ListVaultCandidates returns the vault files in the directory
+// ordered from newest to oldest by their file name timestamps.
+func ListVaultCandidates(vaultDir string) ([]VaultCandidate, error) {
+	files, err := os.ReadDir(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return listCandidates(files, func(name string) string {
+		return filepath.Join(vaultDir, name)
+	})
+}
+
+// SelectVault chooses a vault from the candidates using the policy.
+// The index is only used by the SelectIndex policy.
+//
+// The candidates are expected in the order returned by ListVaultCandidates.
+func SelectVault(candidates []VaultCandidate, policy SelectPolicy, index int) (VaultCandidate, error) {
+	return selectVault(candidates, policy, index, os.ReadFile)
+}
+
+// selectVault is a helper to choose a vault from the candidates,
+// reading the candidates' files with readFile when comparing versions.
+func selectVault(candidates []VaultCandidate, policy SelectPolicy, index int, readFile func(name string) ([]byte, error)) (VaultCandidate, error) {
+	if len(candidates) == 0 {
+		return VaultCandidate{}, errors.New("no vault backup or export file found")
+	}
+
+	var selected VaultCandidate = candidates[0]
+
+	switch policy {
+	case SelectFilenameTime, "":
+		// The candidates are already ordered by file name
+	case SelectModTime:
+		for _, candidate := range candidates[1:] {
+			if candidate.ModTime.After(selected.ModTime) {
+				selected = candidate
+			}
+		}
+	case SelectVersion:
+		version, dbVersion, err := readVersions(selected.Path, readFile)
+		if err != nil {
+			return VaultCandidate{}, err
+		}
+
+		for _, candidate := range candidates[1:] {
+			v, dbV, err := readVersions(candidate.Path, readFile)
+			if err != nil {
+				return VaultCandidate{}, err
+			}
+
+			// Encrypted databases have an unknown version, so only compare plaintext ones
+			var newerDb bool = dbV > 0 && dbVersion > 0 && dbV > dbVersion
+
+			if v > version || (v == version && newerDb) {
+				selected, version, dbVersion = candidate, v, dbV
+			}
+		}
+	case SelectIndex:
+		if index < 0 || index >= len(candidates) {
+			return VaultCandidate{}, fmt.Errorf("vault index %v out of range [0, %v)", index, len(candidates))
+		}
+
+		selected = candidates[index]
+	default:
+		return VaultCandidate{}, fmt.Errorf("unsupported select policy %q", policy)
+	}
+
+	return selected, nil
+}
+
+// FindVaultPathWith returns the filepath of the vault
+// in the directory chosen by the policy.
+func FindVaultPathWith(vaultDir string, policy SelectPolicy, index int) (string, error) {
+	candidates, err := ListVaultCandidates(vaultDir)
+	if err != nil {
+		return "", err
+	}
+
+	selected, err := SelectVault(candidates, policy, index)
+	if err != nil {
+		return "", err
+	}
+
+	return selected.Path, nil
+}
+
+// parseVaultFileName is a helper to parse the timestamp and sequence numbers
+// from a vault file name. ex. aegis-backup-20240625-000002.json
+func parseVaultFileName(name string) VaultCandidate {
+	var candidate VaultCandidate = VaultCandidate{Name: name}
+
+	var groups []string = strings.Split(vaultFileRE.FindStringSubmatch(name)[2], "-")
+
+	var stamp string
+	var rest []string
+
+	switch {
+	case len(groups) >= 2 && len(groups[0]) == 8 && len(groups[1]) == 6:
+		stamp, rest = groups[0]+groups[1], groups[2:]
+	case len(groups[0]) == 14:
+		stamp, rest = groups[0], groups[1:]
+	default:
+		rest = groups
+	}
+
+	if stamp != "" {
+		t, err := time.ParseInLocation("20060102150405", stamp, time.Local)
+		if err == nil {
+			candidate.Time = t
+		} else {
+			rest = groups // Fall back to comparing the raw numbers
+		}
+	}
+
+	for _, group := range rest {
+		n, err := strconv.ParseInt(group, 10, 64)
+		if err != nil {
+			n = 0 // Very long numeric groups are treated as unordered
+		}
+
+		candidate.Seq = append(candidate.Seq, n)
+	}
+
+	return candidate
+}
+
+// listCandidates is a helper to describe the vault files in the directory listing
+// ordered from newest to oldest. The join function builds each file's path.
+func listCandidates(files []fs.DirEntry, join func(name string) string) ([]VaultCandidate, error) {
+	var candidates []VaultCandidate
+
+	for _, file := range files {
+		// Ignore directories and non-vault files
+		if file.IsDir() || !vaultFileRE.MatchString(file.Name()) {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		var candidate VaultCandidate = parseVaultFileName(file.Name())
+		candidate.Path = join(file.Name())
+		candidate.ModTime = info.ModTime()
+
+		candidates = append(candidates, candidate)
+	}
+
+	slices.SortStableFunc(candidates, func(a, b VaultCandidate) int {
+		return compareFileNames(b, a)
+	})
+
+	return candidates, nil
+}
+
+// compareFileNames is a helper to order candidates by their file name timestamps
+// then sequence numbers. Candidates without a timestamp are ordered as oldest.
+func compareFileNames(a, b VaultCandidate) int {
+	switch {
+	case a.Time.IsZero() && !b.Time.IsZero():
+		return -1
+	case !a.Time.IsZero() && b.Time.IsZero():
+		return 1
+	}
+
+	if c := a.Time.Compare(b.Time); c != 0 {
+		return c
+	}
+
+	if c := slices.Compare(a.Seq, b.Seq); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.Name, b.Name)
+}
+
+// readVersions is a helper to read the vault version and,
+// for plaintext vaults, the database version of the file.
+// The database version of encrypted vaults is 0.
+func readVersions(filePath string, readFile func(name string) ([]byte, error)) (int, int, error) {
+	var versions struct {
+		Version int             `json:"version"`
+		Db      json.RawMessage `json:"db"`
+	}
+
+	data, err := readFile(filePath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err = json.Unmarshal(data, &versions); err != nil {
+		return 0, 0, fmt.Errorf("cannot read vault %q: %w", filePath, err)
+	}
+
+	var db struct {
+		Version int `json:"version"`
+	}
+
+	// Encrypted databases are strings and don't expose their version
+	_ = json.Unmarshal(versions.Db, &db)
+
+	return versions.Version, db.Version, nil
+}
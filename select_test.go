@@ -0,0 +1,97 @@
+package avdu_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+)
+
+const exportsDir string = "test/data/exports"
+
+type vectorSelect struct {
+	policy avdu.SelectPolicy
+	index  int
+	name   string
+}
+
+var vectorsSelect []vectorSelect = []vectorSelect{
+	{policy: avdu.SelectFilenameTime, name: "aegis-backup-20240625-000002.json"},
+	{policy: avdu.SelectVersion, name: "aegis-backup-20240625-000002.json"},
+	{policy: avdu.SelectIndex, index: 1, name: "aegis-backup-20240625-000001.json"},
+	{policy: avdu.SelectIndex, index: 2, name: "aegis-export-00000000000001.json"},
+}
+
+func TestListVaultCandidates(t *testing.T) {
+	candidates, err := avdu.ListVaultCandidates(exportsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string = []string{
+		"aegis-backup-20240625-000002.json",
+		"aegis-backup-20240625-000001.json",
+		"aegis-export-00000000000001.json",
+	}
+
+	if len(candidates) != len(want) {
+		t.Fatalf("ListVaultCandidates() = %v candidates; want %v", len(candidates), len(want))
+	}
+
+	for i, candidate := range candidates {
+		if candidate.Name != want[i] {
+			t.Fatalf("[%v] ListVaultCandidates() = %v; want %v", i, candidate.Name, want[i])
+		}
+	}
+}
+
+func TestSelectVault(t *testing.T) {
+	candidates, err := avdu.ListVaultCandidates(exportsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, vector := range vectorsSelect {
+		selected, err := avdu.SelectVault(candidates, vector.policy, vector.index)
+
+		if err != nil || filepath.Base(selected.Path) != vector.name {
+			t.Fatalf("[%v] SelectVault(%v) = %v, %v; want match for %v, nil", i, vector.policy, selected.Name, err, vector.name)
+		}
+	}
+
+	if _, err := avdu.SelectVault(candidates, avdu.SelectIndex, len(candidates)); err == nil {
+		t.Fatal("SelectVault() with an out of range index = nil error; want error")
+	}
+}
+
+type vectorSelectVersion struct {
+	fixtures []string // The fixtures written as backups, newest first
+	want     int      // The index of the selected fixture
+}
+
+var vectorsSelectVersion []vectorSelectVersion = []vectorSelectVersion{
+	{[]string{"test/data/aegis_plain.json", "test/data/aegis_plain_grouped_v3.json"}, 1},
+	{[]string{"test/data/aegis_encrypted.json", "test/data/aegis_plain_grouped_v3.json"}, 0},
+	{[]string{"test/data/aegis_plain_grouped_v3.json", "test/data/aegis_encrypted.json"}, 0},
+	{[]string{"test/data/aegis_plain.json", "test/data/aegis_encrypted.json", "test/data/aegis_plain_grouped_v3.json"}, 2},
+}
+
+func TestSelectVersion(t *testing.T) {
+	for i, vector := range vectorsSelectVersion {
+		var dir string = t.TempDir()
+		var names []string
+
+		for j, fixture := range vector.fixtures {
+			var name string = fmt.Sprintf("aegis-backup-20240625-%06d.json", len(vector.fixtures)-j)
+
+			writeFixture(t, fixture, filepath.Join(dir, name))
+			names = append(names, name)
+		}
+
+		vaultPath, err := avdu.FindVaultPathWith(dir, avdu.SelectVersion, 0)
+		if err != nil || filepath.Base(vaultPath) != names[vector.want] {
+			t.Fatalf("[%v] FindVaultPathWith() = %v, %v; want %v, nil", i, filepath.Base(vaultPath), err, names[vector.want])
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package avdu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sammy-t/avdu/otp"
+	"github.com/sammy-t/avdu/vault"
+)
+
+// ErrSessionLocked is returned when a locked or closed session is used.
+var ErrSessionLocked = errors.New("the session is locked")
+
+// Session is an unlocked vault that caches the master key and decrypted
+// database so codes can be generated and entries edited without
+// re-reading the file or deriving the key again.
+//
+// Saving re-encrypts the database with the same master key and slots.
+// A Session is safe for concurrent use by multiple goroutines.
+type Session struct {
+	mu sync.RWMutex
+
+	path   string                // The file saved to, empty if the session wasn't opened from a file
+	enc    *vault.VaultEncrypted // The last read or saved encrypted vault, nil for plaintext vaults
+	key    *lockedBuffer         // The master key, nil when locked or for plaintext vaults
+	data   *vault.Vault          // The decrypted vault, nil when locked
+	closed bool
+}
+
+// OpenSession reads the vault file at the path and unlocks it with the password.
+// The password is ignored for plaintext vaults.
+func OpenSession(ctx context.Context, filePath string, pwd string, opts vault.KeyOptions) (*Session, error) {
+	file, err := Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var session *Session = &Session{path: filePath, enc: file.enc, data: file.plain}
+
+	if err := session.Unlock(ctx, pwd, opts); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// NewSession creates a session for the encrypted vault using its master key.
+// The session makes its own copy of the master key.
+//
+// Sessions created without a file path must be saved with SaveAs.
+func NewSession(vaultDataEnc *vault.VaultEncrypted, masterKey []byte) (*Session, error) {
+	vaultData, err := vaultDataEnc.DecryptVault(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newLockedBuffer(append([]byte(nil), masterKey...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{enc: vaultDataEnc, key: key, data: vaultData}, nil
+}
+
+// Path returns the file path the session saves to.
+func (s *Session) Path() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.path
+}
+
+// Encrypted reports whether the session's vault is encrypted.
+func (s *Session) Encrypted() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.enc != nil
+}
+
+// Locked reports whether the session needs to be unlocked before it's used.
+func (s *Session) Locked() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.data == nil
+}
+
+// Unlock decrypts the vault with the password if the session is locked.
+func (s *Session) Unlock(ctx context.Context, pwd string, opts vault.KeyOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.closed:
+		return errors.New("the session is closed")
+	case s.data != nil:
+		return nil
+	case pwd == "":
+		return ErrPasswordRequired
+	}
+
+	var pwdBytes []byte = []byte(pwd)
+	defer vault.Wipe(pwdBytes)
+
+	masterKey, err := s.enc.FindMasterKeyContext(ctx, pwdBytes, opts)
+	if err != nil {
+		return err
+	}
+
+	vaultData, err := s.enc.DecryptVault(masterKey)
+	if err != nil {
+		vault.Wipe(masterKey)
+		return err
+	}
+
+	key, err := newLockedBuffer(masterKey)
+	if err != nil {
+		vault.Wipe(masterKey)
+		return err
+	}
+
+	s.key = key
+	s.data = vaultData
+
+	return nil
+}
+
+// Db returns a copy of the decrypted database.
+func (s *Session) Db() (vault.Db, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.data == nil {
+		return vault.Db{}, ErrSessionLocked
+	}
+
+	return s.data.Db.Clone(), nil
+}
+
+// GetOTP generates the OTP of the entry with the uuid.
+func (s *Session) GetOTP(uuid string) (otp.OTP, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.data == nil {
+		return nil, ErrSessionLocked
+	}
+
+	for _, entry := range s.data.Db.Entries {
+		if entry.Uuid == uuid {
+			return GetOTP(entry)
+		}
+	}
+
+	return nil, fmt.Errorf("entry %v not found", uuid)
+}
+
+// GetOTPResults generates OTPs for the entries in the vault.
+// See GetOTPResults for how failed entries are reported.
+func (s *Session) GetOTPResults() (map[string]OTPResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.data == nil {
+		return nil, ErrSessionLocked
+	}
+
+	return GetOTPResults(s.data)
+}
+
+// Update edits a copy of the database with the function and keeps
+// the changes if it returns nil. The changes aren't saved until Save is called.
+func (s *Session) Update(update func(db *vault.Db) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data == nil {
+		return ErrSessionLocked
+	}
+
+	var db vault.Db = s.data.Db.Clone()
+
+	if err := update(&db); err != nil {
+		return err
+	}
+
+	s.data.Db = db
+
+	return nil
+}
+
+// PutEntry adds the entry or replaces the entry with the same uuid.
+// A uuid is generated for entries without one.
+func (s *Session) PutEntry(entry vault.Entry) error {
+	if entry.Uuid == "" {
+		uuid, err := vault.NewUuid()
+		if err != nil {
+			return err
+		}
+
+		entry.Uuid = uuid
+	}
+
+	return s.Update(func(db *vault.Db) error {
+		for i := range db.Entries {
+			if db.Entries[i].Uuid == entry.Uuid {
+				db.Entries[i] = entry
+				return nil
+			}
+		}
+
+		db.Entries = append(db.Entries, entry)
+
+		return nil
+	})
+}
+
+// RemoveEntry removes the entry with the uuid.
+func (s *Session) RemoveEntry(uuid string) error {
+	return s.Update(func(db *vault.Db) error {
+		for i := range db.Entries {
+			if db.Entries[i].Uuid == uuid {
+				db.Entries = append(db.Entries[:i], db.Entries[i+1:]...)
+				return nil
+			}
+		}
+
+		return fmt.Errorf("entry %v not found", uuid)
+	})
+}
+
+// Save writes the vault to the file the session was opened from,
+// re-encrypting it with the same master key and slots.
+func (s *Session) Save() error {
+	if s.Path() == "" {
+		return errors.New("the session has no file path, use SaveAs")
+	}
+
+	return s.SaveAs(s.Path())
+}
+
+// SaveAs writes the vault to the file at the path, re-encrypting it with
+// the same master key and slots, and saves to the path from then on.
+//
+// The file is replaced atomically so a failed save doesn't corrupt it.
+func (s *Session) SaveAs(filePath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data == nil {
+		return ErrSessionLocked
+	}
+
+	var output any = s.data
+
+	if s.enc != nil {
+		vaultDataEnc, err := s.data.Encrypt(s.key.bytes(), s.enc.Header.Slots)
+		if err != nil {
+			return err
+		}
+
+		output = vaultDataEnc
+		s.enc = vaultDataEnc
+	}
+
+	// Marshal with indentation to match Aegis export format
+	data, err := json.MarshalIndent(output, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filePath, data); err != nil {
+		return fmt.Errorf("cannot save vault %q: %w", filePath, err)
+	}
+
+	s.path = filePath
+
+	return nil
+}
+
+// Lock wipes the master key and drops the decrypted database.
+// Unsaved changes are discarded. Plaintext sessions can't be locked.
+func (s *Session) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lock()
+}
+
+// Close locks the session and prevents it from being unlocked again.
+func (s *Session) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lock()
+	s.data = nil
+	s.closed = true
+}
+
+// lock is a helper to wipe the master key. The caller must hold the write lock.
+func (s *Session) lock() {
+	if s.enc == nil {
+		return
+	}
+
+	if s.key != nil {
+		s.key.destroy()
+		s.key = nil
+	}
+
+	s.data = nil
+}
+
+// writeFileAtomic is a helper to write the data to a temporary file
+// in the same directory and rename it over the file.
+func writeFileAtomic(filePath string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filePath)
+}
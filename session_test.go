@@ -0,0 +1,90 @@
+package avdu_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/sammy-t/avdu"
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestSession(t *testing.T) {
+	data, err := os.ReadFile("test/data/aegis_encrypted.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var path string = filepath.Join(t.TempDir(), "aegis-backup-20240625-000001.json")
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := avdu.OpenSession(context.Background(), path, "test", vault.KeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.Close()
+
+	db, err := session.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry vault.Entry = db.Entries[0]
+	entry.Issuer = "Renamed"
+
+	var wg sync.WaitGroup
+
+	// Generate codes while editing to check concurrent use
+	for range 4 {
+		wg.Go(func() {
+			if _, err := session.GetOTPResults(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	if err := session.PutEntry(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+
+	if err := session.RemoveEntry(db.Entries[1].Uuid); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := session.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	session.Lock()
+
+	if _, err := session.Db(); !errors.Is(err, avdu.ErrSessionLocked) {
+		t.Fatalf("Db() after Lock() error = %v; want ErrSessionLocked", err)
+	}
+
+	// The saved file is unlocked with the original password
+	saved, err := avdu.ReadAndDecryptVaultFile(path, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(saved.Db.Entries) != len(db.Entries)-1 || saved.Db.Entries[0].Issuer != "Renamed" {
+		t.Fatalf("Save() wrote %v entries, first issuer %v; want %v, Renamed", len(saved.Db.Entries), saved.Db.Entries[0].Issuer, len(db.Entries)-1)
+	}
+
+	if err := session.Unlock(context.Background(), "test", vault.KeyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := session.GetOTP(entry.Uuid); err != nil {
+		t.Fatalf("GetOTP() after Unlock() error = %v; want nil", err)
+	}
+}
@@ -0,0 +1,238 @@
+package avdu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	UsageSourceAegis string = "aegis" // Usage counts imported from Aegis preferences
+
+	aegisUsagePref string = "pref_usage_count" // The Aegis preference holding the usage counts
+)
+
+// UsageEvent records that an entry's code was used, or a count of uses
+// imported from another app. Events never contain codes or secrets.
+type UsageEvent struct {
+	Time   time.Time `json:"time"`
+	Uuid   string    `json:"uuid"`
+	Count  int       `json:"count,omitempty"`  // The number of uses, 1 when zero
+	Source string    `json:"source,omitempty"` // Where imported counts came from, empty for avdu's own uses
+}
+
+// Uses returns the number of uses the event records.
+func (e UsageEvent) Uses() int {
+	return max(e.Count, 1)
+}
+
+// RecentEntry is an entry's most recent use and its total uses.
+type RecentEntry struct {
+	Uuid     string
+	LastUsed time.Time
+	Uses     int
+}
+
+// ReadUsageLog reads the events from the usage log at the path.
+// A missing log has no events.
+func ReadUsageLog(filePath string) ([]UsageEvent, error) {
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var events []UsageEvent
+
+	scanner := bufio.NewScanner(file)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var event UsageEvent
+
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("cannot read usage log %q line %v: %w", filePath, line, err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, scanner.Err()
+}
+
+// AppendUsage records a use of the entry with the uuid in the usage log at the path,
+// creating the log and its directory if needed.
+func AppendUsage(filePath string, uuid string, t time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(UsageEvent{Time: t.UTC(), Uuid: uuid})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// ImportUsage replaces the counts previously imported from the source
+// in the usage log at the path with the counts.
+func ImportUsage(filePath string, source string, counts map[string]int, t time.Time) error {
+	events, err := ReadUsageLog(filePath)
+	if err != nil {
+		return err
+	}
+
+	events = slices.DeleteFunc(events, func(event UsageEvent) bool {
+		return event.Source == source
+	})
+
+	for _, uuid := range slices.Sorted(maps.Keys(counts)) {
+		if counts[uuid] > 0 {
+			events = append(events, UsageEvent{Time: t.UTC(), Uuid: uuid, Count: counts[uuid], Source: source})
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		buffer.Write(append(data, '\n'))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filePath, buffer.Bytes())
+}
+
+// UsageCounts returns the total uses of each entry by uuid.
+func UsageCounts(events []UsageEvent) map[string]int {
+	var counts map[string]int = make(map[string]int)
+
+	for _, event := range events {
+		counts[event.Uuid] += event.Uses()
+	}
+
+	return counts
+}
+
+// RecentUsage returns the entries used by avdu ordered from most to least
+// recently used. Imported counts are included in the uses
+// but don't make an entry recent.
+func RecentUsage(events []UsageEvent) []RecentEntry {
+	var counts map[string]int = UsageCounts(events)
+	var recent []RecentEntry
+
+	for _, event := range events {
+		if event.Source != "" {
+			continue
+		}
+
+		var i int = slices.IndexFunc(recent, func(entry RecentEntry) bool { return entry.Uuid == event.Uuid })
+
+		if i < 0 {
+			recent = append(recent, RecentEntry{Uuid: event.Uuid, LastUsed: event.Time, Uses: counts[event.Uuid]})
+		} else if event.Time.After(recent[i].LastUsed) {
+			recent[i].LastUsed = event.Time
+		}
+	}
+
+	slices.SortStableFunc(recent, func(a, b RecentEntry) int {
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+
+	return recent
+}
+
+// aegisPrefs is the Android shared preferences file Aegis stores its preferences in.
+type aegisPrefs struct {
+	Strings []aegisPref `xml:"string"`
+}
+
+// aegisPref is a string preference.
+type aegisPref struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ReadAegisUsageCounts reads the usage counts by entry uuid from an Aegis
+// preferences file, de.beemdevelopment.aegis_preferences.xml, or from the
+// json value of its pref_usage_count preference.
+func ReadAegisUsageCounts(r io.Reader) (map[string]int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte = bytes.TrimSpace(data)
+
+	if !bytes.HasPrefix(value, []byte("[")) {
+		var prefs aegisPrefs
+
+		if err := xml.Unmarshal(data, &prefs); err != nil {
+			return nil, fmt.Errorf("cannot read preferences: %w", err)
+		}
+
+		var i int = slices.IndexFunc(prefs.Strings, func(pref aegisPref) bool {
+			return pref.Name == aegisUsagePref
+		})
+
+		if i < 0 {
+			return nil, fmt.Errorf("preferences have no %v", aegisUsagePref)
+		}
+
+		value = []byte(prefs.Strings[i].Value)
+	}
+
+	var usages []struct {
+		Uuid  string `json:"uuid"`
+		Count int    `json:"count"`
+	}
+
+	if err := json.Unmarshal(value, &usages); err != nil {
+		return nil, fmt.Errorf("cannot read %v: %w", aegisUsagePref, err)
+	}
+
+	var counts map[string]int = make(map[string]int)
+
+	for _, usage := range usages {
+		counts[strings.ToLower(usage.Uuid)] += usage.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,94 @@
+package avdu_test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sammy-t/avdu"
+)
+
+const aegisPrefsXml string = `<?xml version='1.0' encoding='utf-8' standalone='yes' ?>
+<map>
+    <boolean name="pref_secure_screen" value="true" />
+    <string name="pref_usage_count">[{&quot;uuid&quot;:&quot;3AE6F1AD-2E65-4ED2-A953-1EC0DFF2386D&quot;,&quot;count&quot;:5},{&quot;uuid&quot;:&quot;84b55971-a3d2-4173-a5bb-0aea113dbc17&quot;,&quot;count&quot;:2}]</string>
+</map>
+`
+
+func TestUsageLog(t *testing.T) {
+	var path string = filepath.Join(t.TempDir(), "avdu", "usage.log")
+	var start time.Time = time.Date(2024, 6, 25, 0, 0, 0, 0, time.UTC)
+
+	events, err := avdu.ReadUsageLog(path)
+	if err != nil || events != nil {
+		t.Fatalf("ReadUsageLog() of a missing log = %v, %v; want nil, nil", events, err)
+	}
+
+	var uses []string = []string{"a", "b", "a", "c"}
+
+	for i, uuid := range uses {
+		if err := avdu.AppendUsage(path, uuid, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := avdu.ReadAegisUsageCounts(strings.NewReader(aegisPrefsXml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wantCounts map[string]int = map[string]int{"3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d": 5, "84b55971-a3d2-4173-a5bb-0aea113dbc17": 2}
+
+	if !maps.Equal(counts, wantCounts) {
+		t.Fatalf("ReadAegisUsageCounts() = %v; want %v", counts, wantCounts)
+	}
+
+	// Importing twice replaces the earlier import
+	counts["a"] = 10
+
+	for range 2 {
+		if err := avdu.ImportUsage(path, avdu.UsageSourceAegis, counts, start); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, err = avdu.ReadUsageLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCounts = map[string]int{"a": 12, "b": 1, "c": 1, "3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d": 5, "84b55971-a3d2-4173-a5bb-0aea113dbc17": 2}
+
+	if got := avdu.UsageCounts(events); !maps.Equal(got, wantCounts) {
+		t.Fatalf("UsageCounts() = %v; want %v", got, wantCounts)
+	}
+
+	var recent []avdu.RecentEntry = avdu.RecentUsage(events)
+	var want []avdu.RecentEntry = []avdu.RecentEntry{
+		{Uuid: "c", LastUsed: start.Add(3 * time.Minute), Uses: 1},
+		{Uuid: "a", LastUsed: start.Add(2 * time.Minute), Uses: 12},
+		{Uuid: "b", LastUsed: start.Add(1 * time.Minute), Uses: 1},
+	}
+
+	if len(recent) != len(want) {
+		t.Fatalf("RecentUsage() = %v; want %v", recent, want)
+	}
+
+	for i := range want {
+		if recent[i].Uuid != want[i].Uuid || !recent[i].LastUsed.Equal(want[i].LastUsed) || recent[i].Uses != want[i].Uses {
+			t.Fatalf("[%v] RecentUsage() = %v; want %v", i, recent[i], want[i])
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "secret") || strings.Count(string(data), "\n") != len(uses)+len(counts) {
+		t.Fatalf("usage log = %s; want a line per use and imported count", data)
+	}
+}
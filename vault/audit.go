@@ -0,0 +1,257 @@
+package vault
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Risk describes how much a finding weakens the vault.
+type Risk string
+
+const (
+	RiskHigh   Risk = "high"
+	RiskMedium Risk = "medium"
+	RiskLow    Risk = "low"
+)
+
+// riskPenalties maps each risk to the points it removes from the audit score.
+var riskPenalties map[Risk]int = map[Risk]int{
+	RiskHigh:   20,
+	RiskMedium: 5,
+	RiskLow:    1,
+}
+
+const (
+	minSecretBits  int = 128     // The minimum secret length recommended by RFC 4226
+	weakSecretBits int = 80      // Secrets shorter than this are considered high risk
+	minMotpPinLen  int = 6       // The minimum motp PIN length considered safe
+	minScryptN     int = 1 << 15 // The scrypt parameters Aegis uses for new password slots
+	minScryptR     int = 8
+	minScryptP     int = 1
+)
+
+// Finding describes a weak configuration found by an audit.
+type Finding struct {
+	Risk    Risk   `json:"risk"`
+	Check   string `json:"check"`          // ex. "secret_length", "duplicate_secret", "algorithm"
+	Subject string `json:"subject"`        // ex. "entry", "slot"
+	Uuid    string `json:"uuid,omitempty"` // The uuid of the entry or slot
+	Name    string `json:"name,omitempty"` // A readable name for the subject
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	var subject string = f.Subject
+
+	if f.Name != "" {
+		subject += " " + f.Name
+	}
+
+	if f.Uuid != "" {
+		subject += fmt.Sprintf(" [%v]", f.Uuid)
+	}
+
+	return fmt.Sprintf("%v: %v: %v", f.Risk, subject, f.Message)
+}
+
+// AuditReport contains the findings of an audit and a score from 0 to 100
+// where 100 means no weak configurations were found.
+type AuditReport struct {
+	Score    int       `json:"score"`
+	Entries  int       `json:"entries"`
+	Slots    int       `json:"slots"`
+	Findings []Finding `json:"findings"`
+}
+
+// Count returns the number of findings with the risk.
+func (r AuditReport) Count(risk Risk) int {
+	var count int
+
+	for _, finding := range r.Findings {
+		if finding.Risk == risk {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (r AuditReport) String() string {
+	var builder strings.Builder
+
+	for _, finding := range r.Findings {
+		fmt.Fprintln(&builder, finding)
+	}
+
+	fmt.Fprintf(&builder, "Score: %v/100 (%v entries, %v slots; %v high, %v medium, %v low)",
+		r.Score, r.Entries, r.Slots, r.Count(RiskHigh), r.Count(RiskMedium), r.Count(RiskLow))
+
+	return builder.String()
+}
+
+// Audit checks the vault's entries and password slots for weak configurations
+// and returns a scored report.
+//
+// Entries are checked for short and duplicate secrets, weak algorithms, 6-digit codes,
+// and short motp PINs. Password slots are checked for weak scrypt parameters.
+// The issuer's supported algorithms aren't known, so SHA1 is reported as a low risk.
+func Audit(v *Vault) AuditReport {
+	var report AuditReport = AuditReport{
+		Score:    100,
+		Entries:  len(v.Db.Entries),
+		Slots:    len(v.Header.Slots),
+		Findings: []Finding{},
+	}
+
+	var secrets map[string][]Entry = make(map[string][]Entry)
+
+	for _, entry := range v.Db.Entries {
+		report.Findings = append(report.Findings, auditEntry(entry)...)
+
+		var secret string = strings.ToUpper(strings.TrimRight(entry.Info.Secret, "="))
+
+		if secret != "" {
+			secrets[secret] = append(secrets[secret], entry)
+		}
+	}
+
+	// Report duplicates in the order the entries appear
+	for _, entry := range v.Db.Entries {
+		var duplicates []Entry = secrets[strings.ToUpper(strings.TrimRight(entry.Info.Secret, "="))]
+
+		if len(duplicates) < 2 || duplicates[0].Uuid != entry.Uuid {
+			continue
+		}
+
+		var names []string
+
+		for _, duplicate := range duplicates[1:] {
+			names = append(names, entryName(duplicate))
+		}
+
+		report.Findings = append(report.Findings, Finding{
+			Risk:    RiskHigh,
+			Check:   "duplicate_secret",
+			Subject: "entry",
+			Uuid:    entry.Uuid,
+			Name:    entryName(entry),
+			Message: fmt.Sprintf("secret is shared with %v", strings.Join(names, ", ")),
+		})
+	}
+
+	for _, slot := range v.Header.Slots {
+		report.Findings = append(report.Findings, auditSlot(slot)...)
+	}
+
+	sortFindings(report.Findings)
+
+	for _, finding := range report.Findings {
+		report.Score -= riskPenalties[finding.Risk]
+	}
+
+	report.Score = max(report.Score, 0)
+
+	return report
+}
+
+// auditEntry is a helper to check the entry's secret, algorithm, digits, and PIN.
+func auditEntry(entry Entry) []Finding {
+	var findings []Finding
+	var info Info = entry.Info
+
+	var add = func(risk Risk, check string, message string) {
+		findings = append(findings, Finding{Risk: risk, Check: check, Subject: "entry", Uuid: entry.Uuid, Name: entryName(entry), Message: message})
+	}
+
+	var secret []byte
+	var err error
+
+	if entry.Type == "motp" {
+		secret, err = hex.DecodeString(info.Secret)
+	} else {
+		secret, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(info.Secret, "=")))
+	}
+
+	if bits := len(secret) * 8; err == nil {
+		switch {
+		case bits < weakSecretBits:
+			add(RiskHigh, "secret_length", fmt.Sprintf("secret is %v bits, at least %v are recommended", bits, minSecretBits))
+		case bits < minSecretBits:
+			add(RiskMedium, "secret_length", fmt.Sprintf("secret is %v bits, at least %v are recommended", bits, minSecretBits))
+		}
+	}
+
+	// motp is defined using MD5 so the algorithm can't be changed
+	if entry.Type != "motp" {
+		switch info.Algo {
+		case "MD5":
+			add(RiskMedium, "algorithm", "uses MD5, use SHA256 or SHA512 if the issuer supports it")
+		case "SHA1":
+			add(RiskLow, "algorithm", "uses SHA1, use SHA256 or SHA512 if the issuer supports it")
+		}
+	}
+
+	// Steam codes have a fixed length
+	if entry.Type != "steam" && info.Digits > 0 && info.Digits <= 6 {
+		add(RiskLow, "digits", fmt.Sprintf("uses %v-digit codes, use 8 digits if the issuer supports it", info.Digits))
+	}
+
+	if entry.Type == "motp" && len(info.Pin) < minMotpPinLen {
+		add(RiskMedium, "pin_length", fmt.Sprintf("PIN is %v characters, at least %v are recommended", len(info.Pin), minMotpPinLen))
+	}
+
+	return findings
+}
+
+// auditSlot is a helper to check the password slot's scrypt parameters.
+func auditSlot(slot Slot) []Finding {
+	// Only password slots derive their key with scrypt
+	if slot.Type != 1 {
+		return nil
+	}
+
+	var weak []string
+
+	if slot.N < minScryptN {
+		weak = append(weak, fmt.Sprintf("n %v < %v", slot.N, minScryptN))
+	}
+
+	if slot.R < minScryptR {
+		weak = append(weak, fmt.Sprintf("r %v < %v", slot.R, minScryptR))
+	}
+
+	if slot.P < minScryptP {
+		weak = append(weak, fmt.Sprintf("p %v < %v", slot.P, minScryptP))
+	}
+
+	if len(weak) == 0 {
+		return nil
+	}
+
+	var finding Finding = Finding{
+		Risk:    RiskHigh,
+		Check:   "scrypt_params",
+		Subject: "slot",
+		Uuid:    slot.Uuid,
+		Message: fmt.Sprintf("weak scrypt parameters: %v", strings.Join(weak, ", ")),
+	}
+
+	return []Finding{finding}
+}
+
+// entryName is a helper to describe the entry by its issuer and name.
+func entryName(entry Entry) string {
+	return fmt.Sprintf("%v (%v)", entry.Issuer, entry.Name)
+}
+
+// sortFindings is a helper to order the findings from highest to lowest risk.
+func sortFindings(findings []Finding) {
+	var order []Risk = []Risk{RiskHigh, RiskMedium, RiskLow}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return slices.Index(order, a.Risk) - slices.Index(order, b.Risk)
+	})
+}
@@ -0,0 +1,56 @@
+package vault_test
+
+import (
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestAudit(t *testing.T) {
+	var vaultData *vault.Vault = readVault(t, "../test/data/aegis_plain.json")
+
+	var report vault.AuditReport = vault.Audit(vaultData)
+
+	if report.Score != 95 || report.Count(vault.RiskLow) != 5 || len(report.Findings) != 5 {
+		t.Fatalf("Audit() = %v; want score 95 with 5 low findings", report)
+	}
+
+	var entries []vault.Entry = vaultData.Db.Entries
+
+	entries[0].Info.Secret = "JBSWY3DPEHPK3PXP" // 80 bits
+	entries[1].Info.Secret = entries[2].Info.Secret
+	entries[2].Info.Algo = "MD5"
+
+	vaultData.Header.Slots = []vault.Slot{{Type: 1, Uuid: "weak", N: 1 << 10, R: 8, P: 1}}
+
+	var vectors = []struct {
+		check string
+		risk  vault.Risk
+		uuid  string
+	}{
+		{"secret_length", vault.RiskMedium, entries[0].Uuid},
+		{"duplicate_secret", vault.RiskHigh, entries[1].Uuid},
+		{"algorithm", vault.RiskMedium, entries[2].Uuid},
+		{"scrypt_params", vault.RiskHigh, "weak"},
+	}
+
+	report = vault.Audit(vaultData)
+
+	for i, vector := range vectors {
+		var found bool
+
+		for _, finding := range report.Findings {
+			if finding.Check == vector.check && finding.Risk == vector.risk && finding.Uuid == vector.uuid {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatalf("[%v] Audit() = %v; want %v %v finding for %v", i, report, vector.risk, vector.check, vector.uuid)
+		}
+	}
+
+	if report.Findings[0].Risk != vault.RiskHigh || report.Score >= 95 {
+		t.Fatalf("Audit() = %v; want high risk findings first and a lower score", report)
+	}
+}
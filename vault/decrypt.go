@@ -1,6 +1,7 @@
 package vault
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/base64"
@@ -14,62 +15,71 @@
 // FindMasterKey uses the password to decrypt the master key
 // from the vault and returns the master key's bytes.
 func (vaultData *VaultEncrypted) FindMasterKey(pwd string) ([]byte, error) {
-	var key []byte
-	var masterKey []byte
+	var pwdBytes []byte = []byte(pwd)
+	defer Wipe(pwdBytes)
 
-	for _, slot := range vaultData.Header.Slots {
-		// Ignore slots that aren't using the password type
-		if slot.Type != 1 {
-			continue
-		}
+	return vaultData.FindMasterKeyBytes(pwdBytes)
+}
+
+// FindMasterKeyBytes uses the password to decrypt the master key
+// from the vault and returns the master key's bytes.
+//
+// The keys derived from the password are wiped before returning
+// but the password is left for the caller to wipe.
+func (vaultData *VaultEncrypted) FindMasterKeyBytes(pwd []byte) ([]byte, error) {
+	return vaultData.FindMasterKeyContext(context.Background(), pwd, KeyOptions{Concurrency: 1})
+}
 
-		salt, err := hex.DecodeString(slot.Salt)
-		if err != nil {
-			return nil, err
-		}
+// errSlotMismatch is returned when a slot can't be decrypted with the password.
+var errSlotMismatch = errors.New("slot doesn't match the password")
 
-		// Create a key using the slot values and provided password
-		key, err = scrypt.Key([]byte(pwd), salt, slot.N, slot.R, slot.P, 32)
-		if err != nil {
-			return nil, err
-		}
+// openSlot is a helper to decrypt the slot's master key using the password,
+// wiping the key derived from the password before returning.
+func openSlot(slot Slot, pwd []byte) ([]byte, error) {
+	salt, err := hex.DecodeString(slot.Salt)
+	if err != nil {
+		return nil, err
+	}
 
-		block, err := aes.NewCipher(key)
-		if err != nil {
-			return nil, err
-		}
+	// Create a key using the slot values and provided password
+	key, err := scrypt.Key(pwd, salt, slot.N, slot.R, slot.P, 32)
+	if err != nil {
+		return nil, err
+	}
 
-		aesgcm, err := cipher.NewGCM(block)
-		if err != nil {
-			return nil, err
-		}
+	defer Wipe(key)
 
-		nonce, err := hex.DecodeString(slot.KeyParams.Nonce)
-		if err != nil {
-			return nil, err
-		}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
 
-		tag, err := hex.DecodeString(slot.KeyParams.Tag)
-		if err != nil {
-			return nil, err
-		}
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
 
-		slotKey, err := hex.DecodeString(slot.Key)
-		if err != nil {
-			return nil, err
-		}
+	nonce, err := hex.DecodeString(slot.KeyParams.Nonce)
+	if err != nil {
+		return nil, err
+	}
 
-		var keyData []byte = append(slotKey, tag...)
+	tag, err := hex.DecodeString(slot.KeyParams.Tag)
+	if err != nil {
+		return nil, err
+	}
 
-		// Attempt to decrypt the master key
-		masterKey, err = aesgcm.Open(nil, nonce, keyData, nil)
-		if err == nil {
-			break
-		}
+	slotKey, err := hex.DecodeString(slot.Key)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(masterKey) == 0 {
-		return nil, errors.New("no master key found")
+	var keyData []byte = append(slotKey, tag...)
+
+	// Attempt to decrypt the master key
+	masterKey, err := aesgcm.Open(nil, nonce, keyData, nil)
+	if err != nil {
+		return nil, errSlotMismatch
 	}
 
 	return masterKey, nil
@@ -125,6 +135,8 @@ func (vaultData *VaultEncrypted) DecryptVault(masterKey []byte) (*Vault, error)
 		return nil, err
 	}
 
+	defer Wipe(content)
+
 	var db Db
 
 	err = json.Unmarshal(content, &db)
@@ -136,6 +148,7 @@ func (vaultData *VaultEncrypted) DecryptVault(masterKey []byte) (*Vault, error)
 		Version: vaultData.Version,
 		Header:  vaultData.Header,
 		Db:      db,
+		Extra:   vaultData.Extra,
 	}
 
 	return &vaultDataPlain, nil
@@ -0,0 +1,232 @@
+package vault
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// ChangeKind describes how an entry changed between two vaults.
+type ChangeKind string
+
+const (
+	ChangeRenamed       ChangeKind = "renamed"        // The issuer or name changed
+	ChangeSecretRotated ChangeKind = "secret_rotated" // The secret or pin changed
+	ChangeGroups        ChangeKind = "groups"         // The group membership changed
+	ChangeOther         ChangeKind = "other"          // Any other field changed
+)
+
+// EntryChange describes an entry present in both vaults with differing content.
+type EntryChange struct {
+	Old   Entry        `json:"old"`
+	New   Entry        `json:"new"`
+	Kinds []ChangeKind `json:"kinds"`
+}
+
+// GroupChange describes a group present in both vaults with a differing name.
+type GroupChange struct {
+	Old Group `json:"old"`
+	New Group `json:"new"`
+}
+
+// DiffResult describes the changes between two vaults.
+type DiffResult struct {
+	Added   []Entry       `json:"added"`
+	Removed []Entry       `json:"removed"`
+	Changed []EntryChange `json:"changed"`
+
+	AddedGroups   []Group       `json:"added_groups"`
+	RemovedGroups []Group       `json:"removed_groups"`
+	RenamedGroups []GroupChange `json:"renamed_groups"`
+}
+
+// Empty reports whether the diff contains no changes.
+func (d DiffResult) Empty() bool {
+	var entriesEmpty bool = len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+	var groupsEmpty bool = len(d.AddedGroups) == 0 && len(d.RemovedGroups) == 0 && len(d.RenamedGroups) == 0
+
+	return entriesEmpty && groupsEmpty
+}
+
+// Redacted returns a copy of the diff with the entries' secrets and pins replaced.
+func (d DiffResult) Redacted() DiffResult {
+	var redacted DiffResult = d
+
+	redacted.Added = redactEntries(d.Added)
+	redacted.Removed = redactEntries(d.Removed)
+	redacted.Changed = nil
+
+	for _, change := range d.Changed {
+		change.Old = change.Old.Redacted()
+		change.New = change.New.Redacted()
+
+		redacted.Changed = append(redacted.Changed, change)
+	}
+
+	return redacted
+}
+
+// Diff compares the vaults' entries by uuid and groups by uuid
+// and returns the changes from a to b.
+//
+// A nil vault is treated as an empty vault.
+func Diff(a *Vault, b *Vault) DiffResult {
+	var diff DiffResult
+
+	var oldEntries map[string]Entry = entriesByUuid(a)
+	var newEntries map[string]Entry = entriesByUuid(b)
+
+	for _, entry := range vaultEntries(b) {
+		oldEntry, ok := oldEntries[entry.Uuid]
+		if !ok {
+			diff.Added = append(diff.Added, entry)
+			continue
+		}
+
+		var kinds []ChangeKind = compareEntries(a.Db, oldEntry, b.Db, entry)
+
+		if len(kinds) > 0 {
+			diff.Changed = append(diff.Changed, EntryChange{Old: oldEntry, New: entry, Kinds: kinds})
+		}
+	}
+
+	for _, entry := range vaultEntries(a) {
+		if _, ok := newEntries[entry.Uuid]; !ok {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+
+	var oldGroups map[string]Group = groupsByUuid(a)
+	var newGroups map[string]Group = groupsByUuid(b)
+
+	for _, group := range vaultGroups(b) {
+		oldGroup, ok := oldGroups[group.Uuid]
+
+		switch {
+		case !ok:
+			diff.AddedGroups = append(diff.AddedGroups, group)
+		case oldGroup.Name != group.Name:
+			diff.RenamedGroups = append(diff.RenamedGroups, GroupChange{Old: oldGroup, New: group})
+		}
+	}
+
+	for _, group := range vaultGroups(a) {
+		if _, ok := newGroups[group.Uuid]; !ok {
+			diff.RemovedGroups = append(diff.RemovedGroups, group)
+		}
+	}
+
+	return diff
+}
+
+// compareEntries is a helper to list the kinds of changes between two entries
+// from their respective databases.
+func compareEntries(aDb Db, a Entry, bDb Db, b Entry) []ChangeKind {
+	var kinds []ChangeKind
+
+	if a.Issuer != b.Issuer || a.Name != b.Name {
+		kinds = append(kinds, ChangeRenamed)
+	}
+
+	if a.Info.Secret != b.Info.Secret || a.Info.Pin != b.Info.Pin {
+		kinds = append(kinds, ChangeSecretRotated)
+	}
+
+	// Compare group uuids when both databases use them, otherwise compare names
+	var groupsChanged bool = !sameGroups(aDb.EntryGroupNames(a), bDb.EntryGroupNames(b))
+
+	if aDb.Version >= 3 && bDb.Version >= 3 {
+		groupsChanged = !sameGroups(a.Groups, b.Groups)
+	}
+
+	if groupsChanged {
+		kinds = append(kinds, ChangeGroups)
+	}
+
+	// Clear the compared fields to check if anything else changed
+	a.Issuer, a.Name, a.Info.Secret, a.Info.Pin, a.Group, a.Groups = "", "", "", "", "", nil
+	b.Issuer, b.Name, b.Info.Secret, b.Info.Pin, b.Group, b.Groups = "", "", "", "", "", nil
+
+	if !reflect.DeepEqual(a.withoutShape(), b.withoutShape()) {
+		kinds = append(kinds, ChangeOther)
+	}
+
+	return kinds
+}
+
+// sameGroups is a helper to compare groups regardless of order.
+func sameGroups(a []string, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+
+	slices.Sort(a)
+	slices.Sort(b)
+
+	return slices.Equal(a, b)
+}
+
+// vaultEntries is a helper to return the vault's entries, allowing a nil vault.
+func vaultEntries(v *Vault) []Entry {
+	if v == nil {
+		return nil
+	}
+
+	return v.Db.Entries
+}
+
+// vaultGroups is a helper to return the vault's groups, allowing a nil vault.
+func vaultGroups(v *Vault) []Group {
+	if v == nil {
+		return nil
+	}
+
+	return v.Db.Groups
+}
+
+// entriesByUuid is a helper to map the vault's entries by uuid.
+func entriesByUuid(v *Vault) map[string]Entry {
+	var entries map[string]Entry = make(map[string]Entry)
+
+	for _, entry := range vaultEntries(v) {
+		entries[entry.Uuid] = entry
+	}
+
+	return entries
+}
+
+// groupsByUuid is a helper to map the vault's groups by uuid.
+func groupsByUuid(v *Vault) map[string]Group {
+	var groups map[string]Group = make(map[string]Group)
+
+	for _, group := range vaultGroups(v) {
+		groups[group.Uuid] = group
+	}
+
+	return groups
+}
+
+// redactEntries is a helper to redact a copy of the entries.
+func redactEntries(entries []Entry) []Entry {
+	var redacted []Entry
+
+	for _, entry := range entries {
+		redacted = append(redacted, entry.Redacted())
+	}
+
+	return redacted
+}
+
+func (d DiffResult) String() string {
+	var outputFormat string = "DiffResult{ added: %v, removed: %v, changed: %v, "
+	outputFormat += "addedGroups: %v, removedGroups: %v, renamedGroups: %v }"
+
+	var fields []any = []any{
+		len(d.Added),
+		len(d.Removed),
+		len(d.Changed),
+		len(d.AddedGroups),
+		len(d.RemovedGroups),
+		len(d.RenamedGroups),
+	}
+
+	return fmt.Sprintf(outputFormat, fields...)
+}
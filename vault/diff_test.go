@@ -0,0 +1,70 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestDiff(t *testing.T) {
+	oldVault := readVault(t, "../test/data/aegis_plain.json")
+	newVault := readVault(t, "../test/data/aegis_plain_grouped_v3.json")
+
+	diff := vault.Diff(oldVault, newVault)
+
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Changed) != 4 || len(diff.AddedGroups) != 2 {
+		t.Fatalf("Diff() = %v; want 4 changed entries and 2 added groups", diff)
+	}
+
+	for i, change := range diff.Changed {
+		if len(change.Kinds) != 1 || change.Kinds[0] != vault.ChangeGroups {
+			t.Fatalf("[%v] Diff() change kinds = %v; want [%v]", i, change.Kinds, vault.ChangeGroups)
+		}
+	}
+
+	if diff := vault.Diff(oldVault, oldVault); !diff.Empty() {
+		t.Fatalf("Diff() of the same vault = %v; want empty", diff)
+	}
+}
+
+func TestDiffRedacted(t *testing.T) {
+	newVault := readVault(t, "../test/data/aegis_plain.json")
+
+	diff := vault.Diff(nil, newVault).Redacted()
+
+	output, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range newVault.Db.Entries {
+		if strings.Contains(string(output), entry.Info.Secret) {
+			t.Fatalf("Redacted() output contains the secret of entry %v", entry.Uuid)
+		}
+	}
+
+	if len(diff.Added) != len(newVault.Db.Entries) {
+		t.Fatalf("Diff() added = %v entries; want %v", len(diff.Added), len(newVault.Db.Entries))
+	}
+}
+
+// readVault is a helper to read a plaintext vault fixture.
+func readVault(t *testing.T, filePath string) *vault.Vault {
+	t.Helper()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vaultData vault.Vault
+
+	if err := json.Unmarshal(data, &vaultData); err != nil {
+		t.Fatal(err)
+	}
+
+	return &vaultData
+}
@@ -0,0 +1,172 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	masterKeyLen int = 32 // The master key length in bytes
+	saltLen      int = 32 // The password slot salt length in bytes
+	nonceLen     int = 12 // The AES-GCM nonce length in bytes
+	tagLen       int = 16 // The AES-GCM tag length in bytes
+
+	// The scrypt parameters Aegis uses for new password slots
+	defScryptN int = 1 << 15
+	defScryptR int = 8
+	defScryptP int = 1
+)
+
+// NewMasterKey generates a random master key.
+func NewMasterKey() ([]byte, error) {
+	var masterKey []byte = make([]byte, masterKeyLen)
+
+	if _, err := rand.Read(masterKey); err != nil {
+		return nil, err
+	}
+
+	return masterKey, nil
+}
+
+// NewPasswordSlot creates a password slot containing the master key
+// encrypted with a key derived from the password.
+func NewPasswordSlot(pwd string, masterKey []byte) (Slot, error) {
+	var salt []byte = make([]byte, saltLen)
+
+	if _, err := rand.Read(salt); err != nil {
+		return Slot{}, err
+	}
+
+	key, err := scrypt.Key([]byte(pwd), salt, defScryptN, defScryptR, defScryptP, 32)
+	if err != nil {
+		return Slot{}, err
+	}
+
+	slotKey, params, err := seal(key, masterKey)
+	if err != nil {
+		return Slot{}, err
+	}
+
+	uuid, err := NewUuid()
+	if err != nil {
+		return Slot{}, err
+	}
+
+	var slot Slot = Slot{
+		Type:      1,
+		Uuid:      uuid,
+		Key:       hex.EncodeToString(slotKey),
+		KeyParams: params,
+		N:         defScryptN,
+		R:         defScryptR,
+		P:         defScryptP,
+		Salt:      hex.EncodeToString(salt),
+		Repaired:  true,
+	}
+
+	return slot, nil
+}
+
+// Encrypt encrypts the vault's database with the master key and
+// returns an encrypted vault with the provided slots.
+//
+// The slots must contain the same master key for the vault to be decryptable.
+func (v *Vault) Encrypt(masterKey []byte, slots []Slot) (*VaultEncrypted, error) {
+	if len(slots) == 0 {
+		return nil, errors.New("at least one slot is required")
+	}
+
+	content, err := json.Marshal(v.Db)
+	if err != nil {
+		return nil, err
+	}
+
+	dbData, params, err := seal(masterKey, content)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt vault: %w", err)
+	}
+
+	var vaultDataEnc VaultEncrypted = VaultEncrypted{
+		Version: v.Version,
+		Header:  Header{Slots: slots, Params: params, Extra: v.Header.Extra},
+		Db:      base64.StdEncoding.EncodeToString(dbData),
+		Extra:   v.Extra,
+	}
+
+	if vaultDataEnc.Version == 0 {
+		vaultDataEnc.Version = 1
+	}
+
+	return &vaultDataEnc, nil
+}
+
+// EncryptWithPassword encrypts the vault using a new master key
+// and a password slot derived from the password.
+func (v *Vault) EncryptWithPassword(pwd string) (*VaultEncrypted, error) {
+	masterKey, err := NewMasterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	slot, err := NewPasswordSlot(pwd, masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Encrypt(masterKey, []Slot{slot})
+}
+
+// NewUuid generates a random version 4 uuid.
+func NewUuid() (string, error) {
+	var data []byte = make([]byte, 16)
+
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	data[6] = (data[6] & 0x0f) | 0x40 // Version 4
+	data[8] = (data[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	var uuid string = hex.EncodeToString(data)
+
+	return fmt.Sprintf("%v-%v-%v-%v-%v", uuid[0:8], uuid[8:12], uuid[12:16], uuid[16:20], uuid[20:32]), nil
+}
+
+// seal is a helper to encrypt the plaintext with AES-GCM and return
+// the ciphertext without the tag along with the nonce and tag params.
+func seal(key []byte, plaintext []byte) ([]byte, Params, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, Params{}, err
+	}
+
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, Params{}, err
+	}
+
+	var nonce []byte = make([]byte, nonceLen)
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, Params{}, err
+	}
+
+	// Aegis stores the tag separately from the ciphertext
+	var sealed []byte = aesgcm.Seal(nil, nonce, plaintext, nil)
+	var ciphertext, tag []byte = sealed[:len(sealed)-tagLen], sealed[len(sealed)-tagLen:]
+
+	var params Params = Params{
+		Nonce: hex.EncodeToString(nonce),
+		Tag:   hex.EncodeToString(tag),
+	}
+
+	return ciphertext, params, nil
+}
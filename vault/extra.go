@@ -0,0 +1,440 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// knownFieldsCache maps struct types to their json fields.
+var knownFieldsCache sync.Map
+
+// knownField is a struct field encoded as a json member.
+type knownField struct {
+	name  string // The member's json name
+	index int    // The field's index in the struct
+}
+
+// memberShape records the known members of a decoded json object that were
+// null or absent, mapping their names to whether they were null.
+//
+// Members are written back the same way while their fields are zero
+// so unchanged vaults are re-encoded as Aegis wrote them.
+type memberShape map[string]bool
+
+// decodeWithExtra decodes the json object into v, a pointer to an alias of a vault type,
+// and returns the object's members that don't match one of the type's fields
+// along with the shape of the members that do.
+func decodeWithExtra(data []byte, v any) (map[string]json.RawMessage, memberShape, error) {
+	// A null object leaves the value zero like encoding/json
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil, nil, nil
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, nil, err
+	}
+
+	var members map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, nil, err
+	}
+
+	var known map[string]knownField = knownFields(reflect.TypeOf(v).Elem())
+	var shape memberShape = make(memberShape)
+
+	for _, field := range known {
+		shape[field.name] = false
+	}
+
+	for name, value := range members {
+		// Field names are matched case-insensitively like encoding/json
+		if field, ok := known[strings.ToLower(name)]; ok {
+			if string(value) == "null" {
+				shape[field.name] = true
+			} else {
+				delete(shape, field.name)
+			}
+
+			delete(members, name)
+			continue
+		}
+
+		// Compact the value so equal members compare equal regardless of formatting
+		var buf bytes.Buffer
+
+		if err := json.Compact(&buf, value); err != nil {
+			return nil, nil, err
+		}
+
+		members[name] = buf.Bytes()
+	}
+
+	if len(shape) == 0 {
+		shape = nil
+	}
+
+	if len(members) == 0 {
+		return nil, shape, nil
+	}
+
+	return members, shape, nil
+}
+
+// encodeWithExtra encodes v, an alias of a vault type, as a json object
+// with the zero fields in the shape written as null or left out,
+// and appends the extra members after the type's fields.
+func encodeWithExtra(v any, extra map[string]json.RawMessage, shape memberShape) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || (len(extra) == 0 && len(shape) == 0) {
+		return data, err
+	}
+
+	var members map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var value reflect.Value = reflect.ValueOf(v)
+
+	buf.WriteByte('{')
+
+	// write is a helper to append a member to the object.
+	write := func(name string, raw []byte) error {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(raw)
+
+		return nil
+	}
+
+	for _, field := range orderedFields(value.Type()) {
+		raw, ok := members[field.name]
+
+		if null, inShape := shape[field.name]; inShape && value.Field(field.index).IsZero() {
+			raw, ok = []byte("null"), null
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := write(field.name, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(extra)) {
+		if err := write(name, extra[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// knownFields is a helper to map the lowercased json names of the struct type's fields to the fields.
+func knownFields(t reflect.Type) map[string]knownField {
+	if known, ok := knownFieldsCache.Load(t); ok {
+		return known.(map[string]knownField)
+	}
+
+	var known map[string]knownField = make(map[string]knownField)
+
+	for _, field := range orderedFields(t) {
+		known[strings.ToLower(field.name)] = field
+	}
+
+	knownFieldsCache.Store(t, known)
+
+	return known
+}
+
+// orderedFields is a helper to list the struct type's json fields in the order they're encoded.
+func orderedFields(t reflect.Type) []knownField {
+	var fields []knownField
+
+	for i := range t.NumField() {
+		var field reflect.StructField = t.Field(i)
+		var name string = field.Name
+
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("json")
+		if ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+
+			if tagName == "-" {
+				continue
+			}
+
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields = append(fields, knownField{name: name, index: i})
+	}
+
+	return fields
+}
+
+// withoutShape is a helper to clear the entry's record of null and absent members
+// so entries decoded from differently written files compare equal.
+func (e Entry) withoutShape() Entry {
+	e.shape = nil
+	e.Info.shape = nil
+
+	return e
+}
+
+// ContentHash returns a hash of the database's entries, groups, and unknown
+// members that ignores which members were written as null or left out,
+// so databases with the same content hash the same however they were written.
+func (d Db) ContentHash() (string, error) {
+	d.shape = nil
+	d.Entries = slices.Clone(d.Entries)
+	d.Groups = slices.Clone(d.Groups)
+
+	for i := range d.Entries {
+		d.Entries[i] = d.Entries[i].withoutShape()
+	}
+
+	for i := range d.Groups {
+		d.Groups[i].shape = nil
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+
+	var sum [sha256.Size]byte = sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (v *Vault) UnmarshalJSON(data []byte) error {
+	type vaultAlias Vault
+
+	var alias vaultAlias
+
+	extra, shape, err := decodeWithExtra(data, &alias)
+	if err != nil {
+		return err
+	}
+
+	alias.Extra = extra
+	alias.shape = shape
+	*v = Vault(alias)
+
+	return nil
+}
+
+func (v Vault) MarshalJSON() ([]byte, error) {
+	type vaultAlias Vault
+
+	return encodeWithExtra(vaultAlias(v), v.Extra, v.shape)
+}
+
+func (v *VaultEncrypted) UnmarshalJSON(data []byte) error {
+	type vaultAlias VaultEncrypted
+
+	var alias vaultAlias
+
+	extra, shape, err := decodeWithExtra(data, &alias)
+	if err != nil {
+		return err
+	}
+
+	alias.Extra = extra
+	alias.shape = shape
+	*v = VaultEncrypted(alias)
+
+	return nil
+}
+
+func (v VaultEncrypted) MarshalJSON() ([]byte, error) {
+	type vaultAlias VaultEncrypted
+
+	return encodeWithExtra(vaultAlias(v), v.Extra, v.shape)
+}
+
+func (h *Header) UnmarshalJSON(data []byte) error {
+	type headerAlias Header
+
+	var alias headerAlias
+
+	extra, shape, err := decodeWithExtra(data, &alias)
+	if err != nil {
+		return err
+	}
+
+	alias.Extra = extra
+	alias.shape = shape
+	*h = Header(alias)
+
+	return nil
+}
+
+func (h Header) MarshalJSON() ([]byte, error) {
+	type headerAlias Header
+
+	var shape memberShape = h.shape
+
+	// Aegis reads a header with params as encrypted, so plaintext headers have null params
+	if _, ok := shape["params"]; !ok {
+		shape = maps.Clone(shape)
+
+		if shape == nil {
+			shape = make(memberShape)
+		}
+
+		shape["params"] = true
+	}
+
+	return encodeWithExtra(headerAlias(h), h.Extra, shape)
+}
+
+func (s *Slot) UnmarshalJSON(data []byte) error {
+	type slotAlias Slot
+
+	var alias slotAlias
+
+	extra, shape, err := decodeWithExtra(data, &alias)
+	if err != nil {
+		return err
+	}
+
+	alias.Extra = extra
+	alias.shape = shape
+	*s = Slot(alias)
+
+	return nil
+}
+
+func (s Slot) MarshalJSON() ([]byte, error) {
+	type slotAlias Slot
+
+	return encodeWithExtra(slotAlias(s), s.Extra, s.shape)
+}
+
+func (p *Params) UnmarshalJSON(data []byte) error {
+	type paramsAlias Params
+
+	var alias paramsAlias
+
+	extra, shape, err := decodeWithExtra(data, &alias)
+	if err != nil {
+		return err
+	}
+
+	alias.Extra = extra
+	alias.shape = shape
+	*p = Params(alias)
+
+	return nil
+}
+
+func (p Params) MarshalJSON() ([]byte, error) {
+	type paramsAlias Params
+
+	return encodeWithExtra(paramsAlias(p), p.Extra, p.shape)
+}
+
+func (d Db) MarshalJSON() ([]byte, error) {
+	type dbAlias Db
+
+	return encodeWithExtra(dbAlias(d), d.Extra, d.shape)
+}
+
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	type entryAlias Entry
+
+	var alias entryAlias
+
+	extra, shape, err := decodeWithExtra(data, &alias)
+	if err != nil {
+		return err
+	}
+
+	alias.Extra = extra
+	alias.shape = shape
+	*e = Entry(alias)
+
+	return nil
+}
+
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entryAlias Entry
+
+	return encodeWithExtra(entryAlias(e), e.Extra, e.shape)
+}
+
+func (i *Info) UnmarshalJSON(data []byte) error {
+	type infoAlias Info
+
+	var alias infoAlias
+
+	extra, shape, err := decodeWithExtra(data, &alias)
+	if err != nil {
+		return err
+	}
+
+	alias.Extra = extra
+	alias.shape = shape
+	*i = Info(alias)
+
+	return nil
+}
+
+func (i Info) MarshalJSON() ([]byte, error) {
+	type infoAlias Info
+
+	return encodeWithExtra(infoAlias(i), i.Extra, i.shape)
+}
+
+func (g *Group) UnmarshalJSON(data []byte) error {
+	type groupAlias Group
+
+	var alias groupAlias
+
+	extra, shape, err := decodeWithExtra(data, &alias)
+	if err != nil {
+		return err
+	}
+
+	alias.Extra = extra
+	alias.shape = shape
+	*g = Group(alias)
+
+	return nil
+}
+
+func (g Group) MarshalJSON() ([]byte, error) {
+	type groupAlias Group
+
+	return encodeWithExtra(groupAlias(g), g.Extra, g.shape)
+}
@@ -0,0 +1,63 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEntryNotFound is returned when no entry matches a query.
+var ErrEntryNotFound = errors.New("entry not found")
+
+// FindEntry returns the entry identified by the query.
+//
+// The query is matched against, in order of preference, the entries' uuids,
+// their "issuer (name)" labels, their issuers or names, then part of their
+// issuers or names, ignoring case. The first kind of match that finds any entry
+// must find exactly one, otherwise an error listing the matches is returned.
+func (d Db) FindEntry(query string) (Entry, error) {
+	var lowerQuery string = strings.ToLower(query)
+
+	var matchers []func(entry Entry) bool = []func(entry Entry) bool{
+		func(entry Entry) bool {
+			return entry.Uuid == query
+		},
+		func(entry Entry) bool {
+			return strings.EqualFold(entryName(entry), query)
+		},
+		func(entry Entry) bool {
+			return strings.EqualFold(entry.Issuer, query) || strings.EqualFold(entry.Name, query)
+		},
+		func(entry Entry) bool {
+			return strings.Contains(strings.ToLower(entry.Issuer), lowerQuery) ||
+				strings.Contains(strings.ToLower(entry.Name), lowerQuery)
+		},
+	}
+
+	for _, matches := range matchers {
+		var found []Entry
+
+		for _, entry := range d.Entries {
+			if matches(entry) {
+				found = append(found, entry)
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		}
+
+		var names []string
+
+		for _, entry := range found {
+			names = append(names, fmt.Sprintf("%v [%v]", entryName(entry), entry.Uuid))
+		}
+
+		return Entry{}, fmt.Errorf("%q matches %v entries: %v", query, len(found), strings.Join(names, ", "))
+	}
+
+	return Entry{}, fmt.Errorf("%q: %w", query, ErrEntryNotFound)
+}
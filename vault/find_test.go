@@ -0,0 +1,48 @@
+package vault_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestFindEntry(t *testing.T) {
+	vaultData := readVault(t, "../test/data/aegis_plain.json")
+
+	type findTest struct {
+		query string
+		want  string // The issuer of the found entry, empty when an error is expected
+	}
+
+	var vectors []findTest = []findTest{
+		{"3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d", "Deno"},
+		{"spdx (james)", "SPDX"},
+		{"DENO", "Deno"},
+		{"canada", "Air Canada"},
+		{"soph", "Boeing"},
+		{"james", ""},
+		{"air", ""},
+		{"missing", ""},
+	}
+
+	for i, vector := range vectors {
+		entry, err := vaultData.Db.FindEntry(vector.query)
+
+		if vector.want == "" {
+			if err == nil {
+				t.Fatalf("[%v] FindEntry(%q) = %v; want error", i, vector.query, entry.Issuer)
+			}
+
+			continue
+		}
+
+		if err != nil || entry.Issuer != vector.want {
+			t.Fatalf("[%v] FindEntry(%q) = %v, %v; want %v, nil", i, vector.query, entry.Issuer, err, vector.want)
+		}
+	}
+
+	if _, err := vaultData.Db.FindEntry("missing"); !errors.Is(err, vault.ErrEntryNotFound) {
+		t.Fatalf("FindEntry(%q) = %v; want %v", "missing", err, vault.ErrEntryNotFound)
+	}
+}
@@ -0,0 +1,207 @@
+package vault_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const goldenDir string = "../test/data/golden"
+
+func TestGolden(t *testing.T) {
+	for _, path := range plainFixtures {
+		var goldenPath string = filepath.Join(goldenDir, filepath.Base(path))
+
+		original, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var vaultData vault.Vault
+
+		if err := json.Unmarshal(original, &vaultData); err != nil {
+			t.Fatal(err)
+		}
+
+		// Marshal with indentation to match Aegis export format
+		output, err := json.MarshalIndent(vaultData, "", "    ")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if *update {
+			if err := os.WriteFile(goldenPath, append(output, '\n'), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		golden, err := os.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(append(output, '\n'), golden) {
+			t.Fatalf("[%v] json.MarshalIndent() doesn't match %v", path, goldenPath)
+		}
+
+		var want, got any
+
+		if err := json.Unmarshal(original, &want); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := json.Unmarshal(output, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		if changed := changedMember(want, got, "$"); changed != "" {
+			t.Fatalf("[%v] json.MarshalIndent() changed %v", path, changed)
+		}
+	}
+}
+
+func TestShapeChanged(t *testing.T) {
+	vaultData := readVault(t, "../test/data/aegis_plain.json")
+
+	// Fields that were null or absent are written once they're set
+	vaultData.Db.Entries[0].Icon = "aWNvbg=="
+	vaultData.Db.Entries[0].Note = "note"
+
+	output, err := json.Marshal(vaultData.Db.Entries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]any
+
+	if err := json.Unmarshal(output, &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry["icon"] != "aWNvbg==" || entry["note"] != "note" {
+		t.Fatalf("json.Marshal() = %s; want the set icon and note", output)
+	}
+
+	if _, ok := entry["icon_mime"]; ok {
+		t.Fatalf("json.Marshal() = %s; want no icon_mime", output)
+	}
+
+	// New plaintext headers have null params like Aegis' plaintext exports
+	output, err = json.Marshal(vault.Header{})
+	if err != nil || string(output) != `{"slots":null,"params":null}` {
+		t.Fatalf("json.Marshal(Header{}) = %s, %v; want null slots and params", output, err)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	var db vault.Db = readVault(t, "../test/data/aegis_plain.json").Db
+
+	want, err := db.ContentHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same database written with absent rather than null members hashes the same
+	var reshaped vault.Db
+
+	if err := json.Unmarshal([]byte(`{"version":1,"entries":[]}`), &reshaped); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range db.Entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var fields map[string]any
+
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatal(err)
+		}
+
+		for name, value := range fields {
+			if value == nil {
+				delete(fields, name)
+			}
+		}
+
+		if data, err = json.Marshal(fields); err != nil {
+			t.Fatal(err)
+		}
+
+		var reshapedEntry vault.Entry
+
+		if err := json.Unmarshal(data, &reshapedEntry); err != nil {
+			t.Fatal(err)
+		}
+
+		reshaped.Entries = append(reshaped.Entries, reshapedEntry)
+	}
+
+	if got, err := reshaped.ContentHash(); err != nil || got != want {
+		t.Fatalf("ContentHash() of the reshaped database = %v, %v; want %v", got, err, want)
+	}
+
+	// Changed content hashes differently
+	db.Entries[0].Name += " changed"
+
+	if got, err := db.ContentHash(); err != nil || got == want {
+		t.Fatalf("ContentHash() of a changed database = %v, %v; want a different hash", got, err)
+	}
+}
+
+// changedMember is a helper to find a value in want that's missing, added,
+// or different in got, including null values, and return its path.
+func changedMember(want any, got any, path string) string {
+	switch want := want.(type) {
+	case map[string]any:
+		gotMap, ok := got.(map[string]any)
+		if !ok {
+			return path
+		}
+
+		for name, value := range want {
+			gotValue, ok := gotMap[name]
+			if !ok {
+				return path + "." + name
+			}
+
+			if changed := changedMember(value, gotValue, path+"."+name); changed != "" {
+				return changed
+			}
+		}
+
+		for name := range gotMap {
+			if _, ok := want[name]; !ok {
+				return path + "." + name
+			}
+		}
+	case []any:
+		gotSlice, ok := got.([]any)
+		if !ok || len(gotSlice) != len(want) {
+			return path
+		}
+
+		for i, value := range want {
+			if changed := changedMember(value, gotSlice[i], fmt.Sprintf("%v[%v]", path, i)); changed != "" {
+				return changed
+			}
+		}
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return path
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,112 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Icon mime types supported by Aegis.
+const (
+	IconMimeSvg  string = "image/svg+xml"
+	IconMimePng  string = "image/png"
+	IconMimeJpeg string = "image/jpeg"
+)
+
+// ErrNoIcon is returned when an entry doesn't have an icon.
+var ErrNoIcon = errors.New("entry has no icon")
+
+// IconExtension returns the file extension for the icon mime type,
+// including the leading dot.
+func IconExtension(mime string) (string, error) {
+	switch mime {
+	case IconMimeSvg:
+		return ".svg", nil
+	case IconMimePng:
+		return ".png", nil
+	case IconMimeJpeg:
+		return ".jpg", nil
+	default:
+		return "", fmt.Errorf("unsupported icon mime type %q", mime)
+	}
+}
+
+// IconMime returns the icon mime type for the file name's extension.
+func IconMime(fileName string) (string, error) {
+	var lower string = strings.ToLower(fileName)
+
+	switch {
+	case strings.HasSuffix(lower, ".svg"):
+		return IconMimeSvg, nil
+	case strings.HasSuffix(lower, ".png"):
+		return IconMimePng, nil
+	case strings.HasSuffix(lower, ".jpg"), strings.HasSuffix(lower, ".jpeg"):
+		return IconMimeJpeg, nil
+	default:
+		return "", fmt.Errorf("unsupported icon file %q", fileName)
+	}
+}
+
+// IconHash returns the hex encoded SHA-256 hash Aegis stores for the icon data.
+func IconHash(data []byte) string {
+	var sum [32]byte = sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// DecodeIcon decodes the entry's base64 icon and checks it against the icon hash.
+//
+// Entries without a hash aren't checked. ErrNoIcon is returned if there's no icon.
+func (e Entry) DecodeIcon() ([]byte, error) {
+	if e.Icon == "" {
+		return nil, ErrNoIcon
+	}
+
+	data, err := base64.StdEncoding.DecodeString(e.Icon)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode icon: %w", err)
+	}
+
+	if e.IconHash != "" && !strings.EqualFold(e.IconHash, IconHash(data)) {
+		return nil, errors.New("icon doesn't match the icon hash")
+	}
+
+	if _, err := IconExtension(e.IconMime); err != nil {
+		return nil, err
+	}
+
+	if e.IconMime != IconMimeSvg && sniffIconMime(data) != e.IconMime {
+		return nil, fmt.Errorf("icon data doesn't match the mime type %q", e.IconMime)
+	}
+
+	return data, nil
+}
+
+// SetIcon replaces the entry's icon with the data, updating the mime type and hash.
+func (e *Entry) SetIcon(data []byte, mime string) error {
+	if _, err := IconExtension(mime); err != nil {
+		return err
+	}
+
+	e.Icon = base64.StdEncoding.EncodeToString(data)
+	e.IconMime = mime
+	e.IconHash = IconHash(data)
+
+	return nil
+}
+
+// sniffIconMime is a helper to detect png and jpeg data from their signatures.
+func sniffIconMime(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return IconMimePng
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return IconMimeJpeg
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,50 @@
+package vault_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestIcon(t *testing.T) {
+	var entry vault.Entry
+	var svg []byte = []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`)
+
+	if _, err := entry.DecodeIcon(); !errors.Is(err, vault.ErrNoIcon) {
+		t.Fatalf("DecodeIcon() error = %v; want ErrNoIcon", err)
+	}
+
+	if err := entry.SetIcon(svg, vault.IconMimeSvg); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := entry.DecodeIcon()
+	if err != nil || !slices.Equal(data, svg) || entry.IconHash != vault.IconHash(svg) {
+		t.Fatalf("DecodeIcon() = %s, %v; want %s, nil", data, err, svg)
+	}
+
+	var vectors = []struct {
+		name   string
+		modify func(entry *vault.Entry)
+	}{
+		{"hash mismatch", func(entry *vault.Entry) { entry.IconHash = vault.IconHash([]byte("other")) }},
+		{"invalid base64", func(entry *vault.Entry) { entry.Icon = "not base64!" }},
+		{"unsupported mime", func(entry *vault.Entry) { entry.IconMime = "image/gif" }},
+		{"png mime for svg data", func(entry *vault.Entry) { entry.IconMime = vault.IconMimePng }},
+	}
+
+	for _, vector := range vectors {
+		var modified vault.Entry = entry
+		vector.modify(&modified)
+
+		if _, err := modified.DecodeIcon(); err == nil {
+			t.Fatalf("[%v] DecodeIcon() error = nil; want an error", vector.name)
+		}
+	}
+
+	if err := entry.SetIcon(svg, "image/gif"); err == nil {
+		t.Fatalf("SetIcon(image/gif) error = nil; want an error")
+	}
+}
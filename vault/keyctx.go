@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// KeyProgress describes the progress of deriving keys for the password slots.
+type KeyProgress struct {
+	Total    int // The number of password slots
+	Started  int // The number of slots whose key derivation has started
+	Finished int // The number of slots whose key derivation has finished
+}
+
+// KeyOptions configures how the master key is searched for.
+type KeyOptions struct {
+	// Concurrency limits the slots tried in parallel. It defaults to the number of CPUs.
+	Concurrency int
+
+	// Progress is called when a slot's key derivation starts or finishes.
+	// Calls are serialized but may come from different goroutines.
+	Progress func(progress KeyProgress)
+}
+
+// FindMasterKeyContext uses the password to decrypt the master key from the vault,
+// trying the password slots in parallel, and returns the master key's bytes.
+//
+// The first slot to succeed cancels the rest. Key derivation can't be interrupted,
+// so a cancelled context stops new slots from starting and returns immediately
+// while slots in progress finish in the background and wipe their keys.
+// The password is left for the caller to wipe once this returns.
+func (vaultData *VaultEncrypted) FindMasterKeyContext(ctx context.Context, pwd []byte, opts KeyOptions) ([]byte, error) {
+	var slots []Slot
+
+	for _, slot := range vaultData.Header.Slots {
+		// Ignore slots that aren't using the password type
+		if slot.Type == 1 {
+			slots = append(slots, slot)
+		}
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The password is copied so background derivations don't read it after it's wiped
+	var pwdCopy []byte = append([]byte(nil), pwd...)
+
+	type slotResult struct {
+		masterKey []byte
+		err       error
+	}
+
+	var results chan slotResult = make(chan slotResult, len(slots))
+	var sem chan struct{} = make(chan struct{}, opts.Concurrency)
+
+	var mu sync.Mutex
+	var progress KeyProgress = KeyProgress{Total: len(slots)}
+
+	var report = func(update func(p *KeyProgress)) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		update(&progress)
+
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	var wg sync.WaitGroup
+	var done chan struct{} = make(chan struct{})
+
+	go func() {
+		defer func() {
+			wg.Wait()
+			Wipe(pwdCopy)
+			close(done)
+		}()
+
+		for _, slot := range slots {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			// Check again in case both cases were ready
+			if ctx.Err() != nil {
+				return
+			}
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				report(func(p *KeyProgress) { p.Started++ })
+
+				masterKey, err := openSlot(slot, pwdCopy)
+
+				report(func(p *KeyProgress) { p.Finished++ })
+
+				results <- slotResult{masterKey: masterKey, err: err}
+			}()
+		}
+	}()
+
+	// Wipe the master keys found by slots that finish after returning
+	var drain = func() {
+		go func() {
+			<-done
+
+			for {
+				select {
+				case result := <-results:
+					Wipe(result.masterKey)
+				default:
+					return
+				}
+			}
+		}()
+	}
+
+	var firstErr error
+
+	for range slots {
+		select {
+		case <-ctx.Done():
+			drain()
+			return nil, ctx.Err()
+		case result := <-results:
+			if result.err == nil {
+				drain()
+				return result.masterKey, nil
+			}
+
+			if !errors.Is(result.err, errSlotMismatch) && firstErr == nil {
+				firstErr = result.err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return nil, errors.New("no master key found")
+}
@@ -0,0 +1,71 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestFindMasterKeyContext(t *testing.T) {
+	masterKey, err := vault.NewMasterKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var slots []vault.Slot
+
+	for _, pwd := range []string{"first", "second", "third"} {
+		slot, err := vault.NewPasswordSlot(pwd, masterKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		slots = append(slots, slot)
+	}
+
+	var plain vault.Vault = vault.Vault{Version: 1, Db: vault.Db{Version: vault.LatestDbVersion}}
+
+	vaultDataEnc, err := plain.Encrypt(masterKey, slots)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var last vault.KeyProgress
+
+	var opts vault.KeyOptions = vault.KeyOptions{
+		Concurrency: 2,
+		Progress: func(progress vault.KeyProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			last = progress
+		},
+	}
+
+	found, err := vaultDataEnc.FindMasterKeyContext(context.Background(), []byte("third"), opts)
+	if err != nil || !slices.Equal(found, masterKey) {
+		t.Fatalf("FindMasterKeyContext() = %v, %v; want the master key, nil", found, err)
+	}
+
+	mu.Lock()
+	if last.Total != 3 || last.Started == 0 {
+		t.Fatalf("FindMasterKeyContext() progress = %+v; want 3 slots with at least 1 started", last)
+	}
+	mu.Unlock()
+
+	if _, err := vaultDataEnc.FindMasterKeyContext(context.Background(), []byte("wrong"), opts); err == nil {
+		t.Fatalf("FindMasterKeyContext(wrong) error = nil; want an error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := vaultDataEnc.FindMasterKeyContext(ctx, []byte("third"), opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("FindMasterKeyContext() with a cancelled context error = %v; want context.Canceled", err)
+	}
+}
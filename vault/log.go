@@ -0,0 +1,89 @@
+package vault
+
+import "log/slog"
+
+// The LogValue methods describe the vault types for structured logging
+// with secrets, pins, and slot keys masked like their String methods.
+// Lists are logged as counts since slog doesn't resolve the values of slice elements.
+
+func (v Vault) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("version", v.Version),
+		slog.Any("header", v.Header),
+		slog.Any("db", v.Db),
+	)
+}
+
+func (v VaultEncrypted) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("version", v.Version),
+		slog.Any("header", v.Header),
+	)
+}
+
+func (h Header) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("slots", len(h.Slots)),
+		slog.Any("params", h.Params),
+	)
+}
+
+func (s Slot) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("type", s.Type),
+		slog.String("uuid", s.Uuid),
+		slog.String("key", mask(s.Key, false)),
+		slog.Int("n", s.N),
+		slog.Int("r", s.R),
+		slog.Int("p", s.P),
+		slog.String("salt", mask(s.Salt, false)),
+		slog.Bool("repaired", s.Repaired),
+		slog.Bool("isBackup", s.IsBackup),
+	)
+}
+
+func (p Params) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("nonce", p.Nonce),
+		slog.String("tag", p.Tag),
+	)
+}
+
+func (d Db) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("version", d.Version),
+		slog.Int("entries", len(d.Entries)),
+		slog.Int("groups", len(d.Groups)),
+	)
+}
+
+func (e Entry) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("type", e.Type),
+		slog.String("uuid", e.Uuid),
+		slog.String("name", e.Name),
+		slog.String("issuer", e.Issuer),
+		slog.Bool("favorite", e.Favorite),
+		slog.Any("info", e.Info),
+		slog.String("group", e.Group),
+		slog.Int("groups", len(e.Groups)),
+	)
+}
+
+func (i Info) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("secret", mask(i.Secret, false)),
+		slog.String("algo", i.Algo),
+		slog.Int("digits", i.Digits),
+		slog.Int("period", i.Period),
+		slog.Int("counter", i.Counter),
+		slog.String("pin", mask(i.Pin, false)),
+	)
+}
+
+func (g Group) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("uuid", g.Uuid),
+		slog.String("name", g.Name),
+	)
+}
@@ -0,0 +1,237 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ConflictPolicy determines which entry is kept when merged vaults
+// contain differing versions of the same entry.
+type ConflictPolicy string
+
+const (
+	PreferNewest      ConflictPolicy = "newest"      // Keep the entry from the later vault
+	PreferLeft        ConflictPolicy = "left"        // Keep the entry from the earlier vault
+	PreferInteractive ConflictPolicy = "interactive" // Keep the entry chosen by the resolve function
+)
+
+// Conflict describes two differing versions of the same entry.
+//
+// Entries conflict when they share a uuid or the same secret, issuer, and name.
+type Conflict struct {
+	Existing Entry // The entry from an earlier vault
+	Incoming Entry // The entry from a later vault
+}
+
+// MergeOptions configures how vaults are merged.
+type MergeOptions struct {
+	Policy ConflictPolicy
+
+	// Resolve chooses the entry to keep for the PreferInteractive policy.
+	Resolve func(conflict Conflict) (Entry, error)
+}
+
+// Merge combines the entries and groups of the vaults into a new plaintext vault.
+//
+// The vaults are expected in order from oldest to newest. Each database is upgraded
+// to the latest version, then groups are matched by name and entries referencing
+// them are updated to the merged group uuids.
+func Merge(vaults []*Vault, opts MergeOptions) (*Vault, error) {
+	if opts.Policy == "" {
+		opts.Policy = PreferNewest
+	}
+
+	switch opts.Policy {
+	case PreferNewest, PreferLeft:
+	case PreferInteractive:
+		if opts.Resolve == nil {
+			return nil, errors.New("the interactive policy requires a resolve function")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported conflict policy %q", opts.Policy)
+	}
+
+	var merger *merger = &merger{
+		opts:       opts,
+		groupNames: make(map[string]string),
+		groupUuids: make(map[string]bool),
+		entryUuids: make(map[string]int),
+		entryKeys:  make(map[string]int),
+	}
+
+	// The empty header has null slots and params, marking the merged vault as plaintext
+	var merged Vault = Vault{Version: 1, Header: Header{}, Db: Db{Version: LatestDbVersion}}
+
+	for _, v := range vaults {
+		merged.Version = max(merged.Version, v.Version)
+
+		db, err := v.Db.Upgrade()
+		if err != nil {
+			return nil, err
+		}
+
+		groupMap, err := merger.mergeGroups(db.Groups)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range db.Entries {
+			if err := merger.mergeEntry(remapGroups(entry, groupMap)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	merged.Db.Entries = merger.entries
+	merged.Db.Groups = merger.groups
+
+	if merged.Db.Groups == nil {
+		merged.Db.Groups = []Group{}
+	}
+
+	return &merged, nil
+}
+
+// merger is a helper to track the merged entries and groups.
+type merger struct {
+	opts MergeOptions
+
+	groups     []Group
+	groupNames map[string]string // The merged group uuids mapped by name
+	groupUuids map[string]bool
+
+	entries    []Entry
+	entryUuids map[string]int // The merged entry indexes mapped by uuid
+	entryKeys  map[string]int // The merged entry indexes mapped by secret, issuer, and name
+}
+
+// mergeGroups is a helper to add the groups to the merged groups
+// and return a map of the groups' uuids to their merged uuids.
+func (m *merger) mergeGroups(groups []Group) (map[string]string, error) {
+	var groupMap map[string]string = make(map[string]string)
+
+	for _, group := range groups {
+		if uuid, ok := m.groupNames[group.Name]; ok {
+			groupMap[group.Uuid] = uuid
+			continue
+		}
+
+		var uuid string = group.Uuid
+
+		// Assign a new uuid when a differently named group already uses it
+		if m.groupUuids[uuid] {
+			var err error
+
+			uuid, err = NewUuid()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		groupMap[group.Uuid] = uuid
+		group.Uuid = uuid
+
+		m.groups = append(m.groups, group)
+		m.groupNames[group.Name] = group.Uuid
+		m.groupUuids[group.Uuid] = true
+	}
+
+	return groupMap, nil
+}
+
+// mergeEntry is a helper to add the entry to the merged entries,
+// resolving any conflict with an existing entry.
+func (m *merger) mergeEntry(entry Entry) error {
+	var key string = entryKey(entry)
+
+	i, ok := m.entryUuids[entry.Uuid]
+	if !ok {
+		i, ok = m.entryKeys[key]
+	}
+
+	if !ok {
+		m.entries = append(m.entries, entry)
+		m.entryUuids[entry.Uuid] = len(m.entries) - 1
+		m.entryKeys[key] = len(m.entries) - 1
+
+		return nil
+	}
+
+	var existing Entry = m.entries[i]
+
+	// Duplicates with a different uuid but otherwise equal content aren't conflicts
+	var compared Entry = entry
+	compared.Uuid = existing.Uuid
+
+	if reflect.DeepEqual(existing.withoutShape(), compared.withoutShape()) {
+		return nil
+	}
+
+	var resolved Entry = existing
+
+	switch m.opts.Policy {
+	case PreferNewest:
+		resolved = entry
+	case PreferInteractive:
+		var err error
+
+		resolved, err = m.opts.Resolve(Conflict{Existing: existing, Incoming: entry})
+		if err != nil {
+			return fmt.Errorf("cannot resolve conflict for %v (%v): %w", entry.Issuer, entry.Name, err)
+		}
+	}
+
+	// Later vaults only match the entry by the uuid and key it's merged with
+	if m.entryUuids[existing.Uuid] == i {
+		delete(m.entryUuids, existing.Uuid)
+	}
+
+	if m.entryKeys[entryKey(existing)] == i {
+		delete(m.entryKeys, entryKey(existing))
+	}
+
+	m.entries[i] = resolved
+
+	if _, ok := m.entryUuids[resolved.Uuid]; !ok {
+		m.entryUuids[resolved.Uuid] = i
+	}
+
+	if _, ok := m.entryKeys[entryKey(resolved)]; !ok {
+		m.entryKeys[entryKey(resolved)] = i
+	}
+
+	return nil
+}
+
+// entryKey is a helper to identify an entry by its secret, issuer, and name.
+func entryKey(entry Entry) string {
+	var fields []string = []string{strings.ToUpper(entry.Info.Secret), entry.Issuer, entry.Name}
+
+	return strings.Join(fields, "\x00")
+}
+
+// remapGroups is a helper to update the entry's group uuids using the map.
+func remapGroups(entry Entry, groupMap map[string]string) Entry {
+	if entry.Groups == nil {
+		return entry
+	}
+
+	var groups []string = make([]string, 0, len(entry.Groups))
+
+	for _, uuid := range entry.Groups {
+		if merged, ok := groupMap[uuid]; ok {
+			uuid = merged
+		}
+
+		if !slices.Contains(groups, uuid) {
+			groups = append(groups, uuid)
+		}
+	}
+
+	entry.Groups = groups
+
+	return entry
+}
@@ -0,0 +1,111 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestMerge(t *testing.T) {
+	plain := readVault(t, "../test/data/aegis_plain.json")
+	grouped := readVault(t, "../test/data/aegis_plain_grouped_v3.json")
+
+	merged, err := vault.Merge([]*vault.Vault{plain, grouped}, vault.MergeOptions{Policy: vault.PreferNewest})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(merged.Db.Entries) != len(grouped.Db.Entries) || len(merged.Db.Groups) != 2 || merged.Db.Version != 3 {
+		t.Fatalf("Merge() = %v entries, %v groups, db version %v; want %v, 2, 3",
+			len(merged.Db.Entries), len(merged.Db.Groups), merged.Db.Version, len(grouped.Db.Entries))
+	}
+
+	// The merged vault is written as a plaintext Aegis export
+	header, err := json.Marshal(merged.Header)
+	if err != nil || string(header) != `{"slots":null,"params":null}` {
+		t.Fatalf("Merge() header = %s, %v; want null slots and params", header, err)
+	}
+
+	// The newest vault's group membership is kept
+	if diff := vault.Diff(grouped, merged); !diff.Empty() {
+		t.Fatalf("Merge() with PreferNewest differs from the newest vault: %v", diff)
+	}
+
+	merged, err = vault.Merge([]*vault.Vault{plain, grouped}, vault.MergeOptions{Policy: vault.PreferLeft})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, entry := range merged.Db.Entries {
+		if len(entry.Groups) != 0 {
+			t.Fatalf("[%v] Merge() with PreferLeft kept groups %v; want none", i, entry.Groups)
+		}
+	}
+}
+
+func TestMergeReplacedEntry(t *testing.T) {
+	var entry vault.Entry = vault.Entry{Uuid: "entry-1", Issuer: "A", Name: "a", Info: vault.Info{Secret: "AAAA"}}
+
+	// The same entry with a new uuid, then with a new secret
+	var renewed vault.Entry = entry
+	renewed.Uuid = "entry-2"
+	renewed.Note = "renewed"
+
+	var rekeyed vault.Entry = renewed
+	rekeyed.Info.Secret = "BBBB"
+
+	// Entries reusing the replaced uuid and key are different entries
+	var reusedUuid vault.Entry = vault.Entry{Uuid: "entry-1", Issuer: "C", Name: "c", Info: vault.Info{Secret: "CCCC"}}
+	var reusedKey vault.Entry = vault.Entry{Uuid: "entry-3", Issuer: "A", Name: "a", Info: vault.Info{Secret: "AAAA"}, Note: "new"}
+
+	var vaults []*vault.Vault
+
+	for _, entries := range [][]vault.Entry{{entry}, {renewed}, {rekeyed}, {reusedUuid, reusedKey}} {
+		vaults = append(vaults, &vault.Vault{Db: vault.Db{Version: vault.LatestDbVersion, Entries: entries}})
+	}
+
+	merged, err := vault.Merge(vaults, vault.MergeOptions{Policy: vault.PreferNewest})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var uuids []string
+
+	for _, entry := range merged.Db.Entries {
+		uuids = append(uuids, entry.Uuid)
+	}
+
+	if len(merged.Db.Entries) != 3 || merged.Db.Entries[0].Info.Secret != "BBBB" || uuids[1] != "entry-1" || uuids[2] != "entry-3" {
+		t.Fatalf("Merge() entries = %v; want [entry-2 entry-1 entry-3] with the replaced entry's secret kept", uuids)
+	}
+}
+
+func TestMergeGroupUuids(t *testing.T) {
+	a := &vault.Vault{Db: vault.Db{
+		Version: 3,
+		Groups:  []vault.Group{{Uuid: "group-a", Name: "Work"}},
+		Entries: []vault.Entry{{Uuid: "entry-1", Issuer: "A", Groups: []string{"group-a"}}},
+	}}
+
+	b := &vault.Vault{Db: vault.Db{
+		Version: 3,
+		Groups:  []vault.Group{{Uuid: "group-b", Name: "Work"}, {Uuid: "group-a", Name: "Home"}},
+		Entries: []vault.Entry{{Uuid: "entry-2", Issuer: "B", Groups: []string{"group-b", "group-a"}}},
+	}}
+
+	merged, err := vault.Merge([]*vault.Vault{a, b}, vault.MergeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(merged.Db.Groups) != 2 || merged.Db.Groups[1].Uuid == "group-a" {
+		t.Fatalf("Merge() groups = %v; want the colliding group reassigned", merged.Db.Groups)
+	}
+
+	var groups []string = merged.Db.Entries[1].Groups
+
+	if len(groups) != 2 || groups[0] != "group-a" || groups[1] != merged.Db.Groups[1].Uuid {
+		t.Fatalf("Merge() entry groups = %v; want [group-a %v]", groups, merged.Db.Groups[1].Uuid)
+	}
+}
@@ -0,0 +1,184 @@
+package vault
+
+import (
+	"fmt"
+	"slices"
+)
+
+// LatestDbVersion is the most recent database version supported.
+//
+// Database versions 1 and 2 store an entry's group as a single group name in Entry.Group.
+// Version 3 stores a list of group uuids in Entry.Groups which reference Db.Groups.
+const LatestDbVersion int = 3
+
+func (d *Db) UnmarshalJSON(data []byte) error {
+	type dbAlias Db // Avoid recursively calling UnmarshalJSON
+
+	var db dbAlias
+
+	// Newer versions are decoded best-effort so their codes can still be shown
+	extra, shape, err := decodeWithExtra(data, &db)
+	if err != nil {
+		return err
+	}
+
+	db.Extra = extra
+	db.shape = shape
+	*d = Db(db)
+
+	return nil
+}
+
+// Upgrade returns a copy of the database migrated to the latest version.
+func (d Db) Upgrade() (Db, error) {
+	return d.Migrate(LatestDbVersion)
+}
+
+// Migrate returns a copy of the database converted to the target version.
+//
+// Group names are converted to group uuids when upgrading to version 3.
+// Downgrading from version 3 fails if an entry belongs to multiple groups,
+// and downgrading to version 1 fails if an entry belongs to any group,
+// since the groups can't be represented without losing data.
+// Databases newer than LatestDbVersion can't be migrated.
+func (d Db) Migrate(version int) (Db, error) {
+	if version < 1 || version > LatestDbVersion {
+		return Db{}, fmt.Errorf("unsupported database version %v", version)
+	}
+
+	if d.Version > LatestDbVersion {
+		return Db{}, fmt.Errorf("cannot migrate database version %v, newer than the supported version %v", d.Version, LatestDbVersion)
+	}
+
+	var db Db = d.Clone()
+	var err error
+
+	if db.Version < 3 && version >= 3 {
+		db, err = groupNamesToUuids(db)
+	} else if db.Version >= 3 && version < 3 {
+		db, err = groupUuidsToNames(db)
+	}
+
+	if err != nil {
+		return Db{}, err
+	}
+
+	if version < 2 {
+		for _, entry := range db.Entries {
+			if entry.Group != "" {
+				return Db{}, fmt.Errorf("entry %v (%v) has group %q which version %v can't store", entry.Issuer, entry.Name, entry.Group, version)
+			}
+		}
+	}
+
+	db.Version = version
+
+	return db, nil
+}
+
+// EntryGroupNames returns the names of the groups the entry belongs to
+// using the group representation of the database's version.
+func (d Db) EntryGroupNames(entry Entry) []string {
+	if d.Version < 3 {
+		if entry.Group == "" {
+			return nil
+		}
+
+		return []string{entry.Group}
+	}
+
+	var names []string
+
+	for _, uuid := range entry.Groups {
+		for _, group := range d.Groups {
+			if group.Uuid == uuid {
+				names = append(names, group.Name)
+				break
+			}
+		}
+	}
+
+	return names
+}
+
+// groupNamesToUuids is a helper to create groups for the entries' group names
+// and reference them by uuid.
+func groupNamesToUuids(db Db) (Db, error) {
+	var uuids map[string]string = make(map[string]string)
+
+	for _, group := range db.Groups {
+		uuids[group.Name] = group.Uuid
+	}
+
+	for i, entry := range db.Entries {
+		var groups []string = []string{}
+
+		if entry.Group != "" {
+			uuid, ok := uuids[entry.Group]
+			if !ok {
+				var err error
+
+				uuid, err = NewUuid()
+				if err != nil {
+					return Db{}, err
+				}
+
+				uuids[entry.Group] = uuid
+				db.Groups = append(db.Groups, Group{Uuid: uuid, Name: entry.Group})
+			}
+
+			groups = append(groups, uuid)
+		}
+
+		entry.Group = ""
+		entry.Groups = groups
+
+		db.Entries[i] = entry
+	}
+
+	if db.Groups == nil {
+		db.Groups = []Group{}
+	}
+
+	return db, nil
+}
+
+// groupUuidsToNames is a helper to replace the entries' group uuids
+// with the name of their only group.
+func groupUuidsToNames(db Db) (Db, error) {
+	for i, entry := range db.Entries {
+		var names []string = db.EntryGroupNames(entry)
+
+		if len(names) > 1 {
+			return Db{}, fmt.Errorf("entry %v (%v) belongs to %v groups but only one can be stored", entry.Issuer, entry.Name, len(names))
+		}
+
+		entry.Group = ""
+		entry.Groups = nil
+
+		if len(names) == 1 {
+			entry.Group = names[0]
+		}
+
+		db.Entries[i] = entry
+	}
+
+	db.Groups = nil
+
+	return db, nil
+}
+
+// Clone returns a copy of the database whose entries and groups
+// can be modified without changing the original.
+func (d Db) Clone() Db {
+	var db Db = d
+
+	db.Groups = slices.Clone(d.Groups)
+	db.Entries = slices.Clone(d.Entries)
+
+	for i, entry := range db.Entries {
+		db.Entries[i].Groups = slices.Clone(entry.Groups)
+	}
+
+	return db
+}
@@ -0,0 +1,183 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+var plainFixtures []string = []string{
+	"../test/data/aegis_example.json",
+	"../test/data/aegis_example_grouped_v3.json",
+	"../test/data/aegis_plain.json",
+	"../test/data/aegis_plain_grouped_v2.json",
+	"../test/data/aegis_plain_grouped_v3.json",
+	"../test/data/aegis_plain_unknown_fields.json",
+	"../test/data/exports/aegis-backup-20240625-000001.json",
+	"../test/data/exports/aegis-export-00000000000001.json",
+}
+
+var encryptedFixtures []string = []string{
+	"../test/data/aegis_encrypted.json",
+	"../test/data/exports/aegis-backup-20240625-000002.json",
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, fixture := range fixtureVaults(t) {
+		data, err := json.Marshal(fixture.vault)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded vault.Vault
+
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("[%v] json.Unmarshal() = %v; want nil", fixture.path, err)
+		}
+
+		if !reflect.DeepEqual(*fixture.vault, decoded) {
+			t.Fatalf("[%v] round trip = %v; want %v", fixture.path, decoded, fixture.vault)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	for _, fixture := range fixtureVaults(t) {
+		var db vault.Db = fixture.vault.Db
+
+		upgraded, err := db.Upgrade()
+		if err != nil || upgraded.Version != vault.LatestDbVersion {
+			t.Fatalf("[%v] Upgrade() = version %v, %v; want %v, nil", fixture.path, upgraded.Version, err, vault.LatestDbVersion)
+		}
+
+		assertGroupNames(t, fixture.path, db, upgraded)
+
+		// Downgrade back to the original version when it can represent the groups
+		restored, err := upgraded.Migrate(db.Version)
+		if err != nil {
+			if db.Version < 3 {
+				t.Fatalf("[%v] Migrate(%v) = %v; want nil", fixture.path, db.Version, err)
+			}
+
+			continue
+		}
+
+		assertGroupNames(t, fixture.path, db, restored)
+
+		if db.Version < 3 && !reflect.DeepEqual(db, restored) {
+			t.Fatalf("[%v] Migrate(%v) = %v; want %v", fixture.path, db.Version, restored, db)
+		}
+	}
+}
+
+func TestMigrateLossy(t *testing.T) {
+	grouped := readVault(t, "../test/data/aegis_plain_grouped_v3.json")
+
+	if _, err := grouped.Db.Migrate(2); err == nil {
+		t.Fatal("Migrate(2) with multiple groups per entry = nil; want error")
+	}
+
+	grouped = readVault(t, "../test/data/aegis_plain_grouped_v2.json")
+
+	if _, err := grouped.Db.Migrate(1); err == nil {
+		t.Fatal("Migrate(1) with grouped entries = nil; want error")
+	}
+
+	if _, err := grouped.Db.Migrate(vault.LatestDbVersion + 1); err == nil {
+		t.Fatalf("Migrate(%v) = nil; want error", vault.LatestDbVersion+1)
+	}
+}
+
+func TestNewerDbVersion(t *testing.T) {
+	var vaultData *vault.Vault = readVault(t, "../test/data/aegis_plain_grouped_v3.json")
+
+	vaultData.Db.Version = vault.LatestDbVersion + 1
+
+	data, err := json.Marshal(vaultData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Vaults from newer versions of Aegis are still read
+	var decoded vault.Vault
+
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Db.Entries) != len(vaultData.Db.Entries) {
+		t.Fatalf("json.Unmarshal() = %v; want the entries and nil", err)
+	}
+
+	if _, err := decoded.Db.Upgrade(); err == nil {
+		t.Fatalf("Upgrade() of version %v = nil; want error", decoded.Db.Version)
+	}
+
+	var problems []vault.Problem = vault.Validate(&decoded)
+
+	if len(problems) != 1 || problems[0].Severity != vault.SeverityWarning || problems[0].Field != "version" {
+		t.Fatalf("Validate() = %v; want a version warning", problems)
+	}
+}
+
+type fixtureVault struct {
+	path  string
+	vault *vault.Vault
+}
+
+// fixtureVaults is a helper to read every plaintext fixture
+// and decrypt every encrypted fixture.
+func fixtureVaults(t *testing.T) []fixtureVault {
+	t.Helper()
+
+	var fixtures []fixtureVault
+
+	for _, path := range plainFixtures {
+		fixtures = append(fixtures, fixtureVault{path: path, vault: readVault(t, path)})
+	}
+
+	for _, path := range encryptedFixtures {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var vaultDataEnc vault.VaultEncrypted
+
+		if err := json.Unmarshal(data, &vaultDataEnc); err != nil {
+			t.Fatal(err)
+		}
+
+		masterKey, err := vaultDataEnc.FindMasterKey("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vaultData, err := vaultDataEnc.DecryptVault(masterKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fixtures = append(fixtures, fixtureVault{path: path, vault: vaultData})
+	}
+
+	return fixtures
+}
+
+// assertGroupNames is a helper to check that each entry belongs
+// to the same named groups in both databases.
+func assertGroupNames(t *testing.T, path string, want vault.Db, got vault.Db) {
+	t.Helper()
+
+	for i, entry := range want.Entries {
+		var wantNames []string = want.EntryGroupNames(entry)
+		var gotNames []string = got.EntryGroupNames(got.Entries[i])
+
+		slices.Sort(wantNames)
+		slices.Sort(gotNames)
+
+		if !slices.Equal(wantNames, gotNames) {
+			t.Fatalf("[%v][%v] group names = %v; want %v", path, i, gotNames, wantNames)
+		}
+	}
+}
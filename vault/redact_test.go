@@ -0,0 +1,64 @@
+package vault_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestStringRedacted(t *testing.T) {
+	for _, fixture := range fixtureVaults(t) {
+		var vaultData *vault.Vault = fixture.vault
+
+		var buf bytes.Buffer
+		var logger *slog.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+
+		logger.Info("vault", "vault", vaultData, "header", vaultData.Header)
+
+		// Vault values are masked like pointers rather than logged with MarshalJSON
+		logger.Info("vault", "vault", *vaultData)
+
+		var outputs []string = []string{
+			vaultData.String(),
+			fmt.Sprint(vaultData),
+			fmt.Sprintf("%+v", vaultData.Db),
+			fmt.Sprintf("%#v", vaultData.Header.Slots),
+			buf.String(),
+		}
+
+		var revealed string = vaultData.Reveal()
+
+		for _, entry := range vaultData.Db.Entries {
+			buf.Reset()
+			logger.Info("entry", "entry", entry)
+
+			outputs = append(outputs, entry.String(), fmt.Sprintf("%#v", entry.Info), buf.String())
+
+			for i, output := range outputs {
+				if strings.Contains(output, entry.Info.Secret) {
+					t.Fatalf("[%v] %v output %v contains the secret of %v (%v)", fixture.path, i, output, entry.Issuer, entry.Name)
+				}
+			}
+
+			if !strings.Contains(revealed, entry.Info.Secret) {
+				t.Fatalf("[%v] Reveal() doesn't contain the secret of %v (%v)", fixture.path, entry.Issuer, entry.Name)
+			}
+		}
+
+		for _, slot := range vaultData.Header.Slots {
+			for i, output := range outputs {
+				if strings.Contains(output, slot.Key) || strings.Contains(output, slot.Salt) {
+					t.Fatalf("[%v] %v output %v contains the key of slot %v", fixture.path, i, output, slot.Uuid)
+				}
+			}
+
+			if !strings.Contains(revealed, slot.Key) {
+				t.Fatalf("[%v] Reveal() doesn't contain the key of slot %v", fixture.path, slot.Uuid)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package vault
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortMode determines the order entries are displayed in.
+type SortMode string
+
+const (
+	SortCustom SortMode = "custom" // The order of the entries in the vault, as arranged in Aegis
+	SortIssuer SortMode = "issuer" // By issuer then name
+	SortName   SortMode = "name"   // By name then issuer
+	SortUsage  SortMode = "usage"  // By usage count, most used first
+)
+
+// SortOptions configures how entries are sorted.
+type SortOptions struct {
+	Mode           SortMode
+	FavoritesFirst bool           // Place favorite entries before the others
+	Usage          map[string]int // Usage counts by entry uuid for SortUsage
+}
+
+// EntryGroup is a group heading and the entries that belong to it.
+//
+// The entries without a group are listed under a zero Group.
+type EntryGroup struct {
+	Group   Group
+	Entries []Entry
+}
+
+// ParseSortMode returns the sort mode matching the name.
+func ParseSortMode(name string) (SortMode, error) {
+	var mode SortMode = SortMode(strings.ToLower(name))
+
+	switch mode {
+	case SortCustom, SortIssuer, SortName, SortUsage:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported sort mode %q", name)
+	}
+}
+
+// SortEntries returns a copy of the entries sorted by the options.
+//
+// Entries that compare equal keep their vault order.
+func SortEntries(entries []Entry, opts SortOptions) []Entry {
+	var sorted []Entry = slices.Clone(entries)
+
+	slices.SortStableFunc(sorted, func(a, b Entry) int {
+		if opts.FavoritesFirst && a.Favorite != b.Favorite {
+			if a.Favorite {
+				return -1
+			}
+
+			return 1
+		}
+
+		switch opts.Mode {
+		case SortIssuer:
+			return cmp.Or(compareFold(a.Issuer, b.Issuer), compareFold(a.Name, b.Name))
+		case SortName:
+			return cmp.Or(compareFold(a.Name, b.Name), compareFold(a.Issuer, b.Issuer))
+		case SortUsage:
+			return cmp.Compare(opts.Usage[b.Uuid], opts.Usage[a.Uuid])
+		default:
+			return 0
+		}
+	})
+
+	return sorted
+}
+
+// GroupedEntries returns the database's entries sorted by the options
+// and listed under each group they belong to.
+//
+// Groups are ordered as in Db.Groups, or by first use for database versions
+// without groups, and groups without entries are omitted. Entries in several
+// groups are listed under each one, and entries without a group are listed
+// last under a zero Group.
+func (d Db) GroupedEntries(opts SortOptions) []EntryGroup {
+	var groups []EntryGroup
+	var indexes map[string]int = make(map[string]int) // Group indexes by name
+
+	for _, group := range d.Groups {
+		if _, ok := indexes[group.Name]; !ok {
+			indexes[group.Name] = len(groups)
+			groups = append(groups, EntryGroup{Group: group})
+		}
+	}
+
+	var ungrouped EntryGroup
+
+	for _, entry := range SortEntries(d.Entries, opts) {
+		var names []string = d.EntryGroupNames(entry)
+
+		if len(names) == 0 {
+			ungrouped.Entries = append(ungrouped.Entries, entry)
+			continue
+		}
+
+		for _, name := range names {
+			i, ok := indexes[name]
+			if !ok {
+				i = len(groups)
+				indexes[name] = i
+				groups = append(groups, EntryGroup{Group: Group{Name: name}})
+			}
+
+			// Skip duplicate references to the same group
+			if !slices.ContainsFunc(groups[i].Entries, func(e Entry) bool { return e.Uuid == entry.Uuid }) {
+				groups[i].Entries = append(groups[i].Entries, entry)
+			}
+		}
+	}
+
+	groups = slices.DeleteFunc(groups, func(group EntryGroup) bool {
+		return len(group.Entries) == 0
+	})
+
+	if len(ungrouped.Entries) > 0 {
+		groups = append(groups, ungrouped)
+	}
+
+	return groups
+}
+
+// compareFold is a helper to compare strings case-insensitively.
+func compareFold(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
@@ -0,0 +1,70 @@
+package vault_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+var sortEntries []vault.Entry = []vault.Entry{
+	{Uuid: "1", Issuer: "b", Name: "y"},
+	{Uuid: "2", Issuer: "A", Name: "z", Favorite: true},
+	{Uuid: "3", Issuer: "c", Name: "x"},
+	{Uuid: "4", Issuer: "a", Name: "w"},
+}
+
+var vectorsSort = []struct {
+	opts vault.SortOptions
+	want []string
+}{
+	{vault.SortOptions{Mode: vault.SortCustom}, []string{"1", "2", "3", "4"}},
+	{vault.SortOptions{Mode: vault.SortIssuer}, []string{"4", "2", "1", "3"}},
+	{vault.SortOptions{Mode: vault.SortName}, []string{"4", "3", "1", "2"}},
+	{vault.SortOptions{Mode: vault.SortName, FavoritesFirst: true}, []string{"2", "4", "3", "1"}},
+	{vault.SortOptions{Mode: vault.SortUsage, Usage: map[string]int{"3": 5, "4": 2}}, []string{"3", "4", "1", "2"}},
+}
+
+func TestSortEntries(t *testing.T) {
+	for i, vector := range vectorsSort {
+		var uuids []string
+
+		for _, entry := range vault.SortEntries(sortEntries, vector.opts) {
+			uuids = append(uuids, entry.Uuid)
+		}
+
+		if !slices.Equal(uuids, vector.want) {
+			t.Fatalf("[%v] SortEntries(%+v) = %v; want %v", i, vector.opts, uuids, vector.want)
+		}
+	}
+}
+
+func TestGroupedEntries(t *testing.T) {
+	for _, path := range []string{"../test/data/aegis_plain_grouped_v2.json", "../test/data/aegis_plain_grouped_v3.json"} {
+		var vaultData *vault.Vault = readVault(t, path)
+		var groups []vault.EntryGroup = vaultData.Db.GroupedEntries(vault.SortOptions{})
+
+		var listed map[string]int = make(map[string]int)
+
+		for i, group := range groups {
+			// Only the last group lists the entries without a group
+			if (group.Group.Name == "") != (i == len(groups)-1) {
+				t.Fatalf("[%v] GroupedEntries() group %v = %q; want the ungrouped entries last", path, i, group.Group.Name)
+			}
+
+			for _, entry := range group.Entries {
+				listed[entry.Uuid]++
+
+				if group.Group.Name != "" && !slices.Contains(vaultData.Db.EntryGroupNames(entry), group.Group.Name) {
+					t.Fatalf("[%v] GroupedEntries() listed %v under %v", path, entry.Uuid, group.Group.Name)
+				}
+			}
+		}
+
+		for _, entry := range vaultData.Db.Entries {
+			if want := max(len(vaultData.Db.EntryGroupNames(entry)), 1); listed[entry.Uuid] != want {
+				t.Fatalf("[%v] GroupedEntries() listed %v %v times; want %v", path, entry.Uuid, listed[entry.Uuid], want)
+			}
+		}
+	}
+}
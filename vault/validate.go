@@ -0,0 +1,314 @@
+package vault
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// Severity describes how serious a problem is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The vault or entry can't be used as is
+	SeverityWarning Severity = "warning" // The vault or entry is usable but suspicious
+)
+
+var otpTypes []string = []string{"totp", "hotp", "steam", "motp"}
+var otpAlgos []string = []string{"SHA1", "SHA256", "SHA512", "MD5"}
+
+// Problem describes an issue found when validating a vault.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Subject  string   `json:"subject"`        // ex. "entry", "slot", "group", "header", "db"
+	Uuid     string   `json:"uuid,omitempty"` // The uuid of the entry, slot, or group
+	Name     string   `json:"name,omitempty"` // A readable name for the subject
+	Field    string   `json:"field,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (p Problem) String() string {
+	var subject string = p.Subject
+
+	if p.Name != "" {
+		subject += " " + p.Name
+	}
+
+	if p.Uuid != "" {
+		subject += fmt.Sprintf(" [%v]", p.Uuid)
+	}
+
+	if p.Field != "" {
+		subject += " " + p.Field
+	}
+
+	return fmt.Sprintf("%v: %v: %v", p.Severity, subject, p.Message)
+}
+
+// Validate checks the plaintext vault's database and header
+// and returns every problem found.
+func Validate(v *Vault) []Problem {
+	var problems []Problem
+
+	if len(v.Header.Slots) > 0 || v.Header.Params.Nonce != "" || v.Header.Params.Tag != "" {
+		problems = append(problems, Problem{
+			Severity: SeverityWarning,
+			Subject:  "header",
+			Message:  "plaintext vault has slots or params which are normally empty",
+		})
+	}
+
+	problems = append(problems, validateDb(v.Db)...)
+
+	return problems
+}
+
+// ValidateHeader checks the encrypted vault's slots and params
+// and returns every problem found.
+func ValidateHeader(h Header) []Problem {
+	var problems []Problem
+	var uuids map[string]bool = make(map[string]bool)
+	var hasPassword bool
+
+	if len(h.Slots) == 0 {
+		problems = append(problems, Problem{Severity: SeverityError, Subject: "header", Field: "slots", Message: "no slots"})
+	}
+
+	for _, slot := range h.Slots {
+		var add = func(severity Severity, field string, message string) {
+			problems = append(problems, Problem{Severity: severity, Subject: "slot", Uuid: slot.Uuid, Field: field, Message: message})
+		}
+
+		if slot.Uuid == "" {
+			add(SeverityError, "uuid", "missing uuid")
+		} else if uuids[slot.Uuid] {
+			add(SeverityError, "uuid", "duplicate uuid")
+		}
+
+		uuids[slot.Uuid] = true
+
+		switch slot.Type {
+		case 0, 2: // Raw and biometric slots
+		case 1:
+			hasPassword = true
+
+			if msg := checkHex(slot.Salt, saltLen); msg != "" {
+				add(SeverityError, "salt", msg)
+			}
+
+			if slot.N <= 1 || slot.N&(slot.N-1) != 0 {
+				add(SeverityError, "n", fmt.Sprintf("scrypt n %v isn't a power of 2 greater than 1", slot.N))
+			}
+
+			if slot.R <= 0 || slot.P <= 0 {
+				add(SeverityError, "r/p", fmt.Sprintf("scrypt r %v and p %v must be positive", slot.R, slot.P))
+			}
+		default:
+			add(SeverityWarning, "type", fmt.Sprintf("unknown slot type %v", slot.Type))
+		}
+
+		if msg := checkHex(slot.Key, masterKeyLen); msg != "" {
+			add(SeverityError, "key", msg)
+		}
+
+		for _, problem := range validateParams(slot.KeyParams) {
+			add(problem.Severity, "key_params."+problem.Field, problem.Message)
+		}
+	}
+
+	if len(h.Slots) > 0 && !hasPassword {
+		problems = append(problems, Problem{
+			Severity: SeverityWarning,
+			Subject:  "header",
+			Field:    "slots",
+			Message:  "no password slot, the vault can't be unlocked with a password",
+		})
+	}
+
+	for _, problem := range validateParams(h.Params) {
+		problem.Subject = "header"
+		problem.Field = "params." + problem.Field
+
+		problems = append(problems, problem)
+	}
+
+	return problems
+}
+
+// validateDb is a helper to check the database's entries and groups.
+func validateDb(db Db) []Problem {
+	var problems []Problem
+
+	if db.Version < 1 {
+		problems = append(problems, Problem{
+			Severity: SeverityError,
+			Subject:  "db",
+			Field:    "version",
+			Message:  fmt.Sprintf("unsupported database version %v", db.Version),
+		})
+	}
+
+	if db.Version > LatestDbVersion {
+		problems = append(problems, Problem{
+			Severity: SeverityWarning,
+			Subject:  "db",
+			Field:    "version",
+			Message:  fmt.Sprintf("database version %v is newer than the supported version %v, so it's read best-effort", db.Version, LatestDbVersion),
+		})
+	}
+
+	var groupUuids map[string]bool = make(map[string]bool)
+
+	for _, group := range db.Groups {
+		var add = func(severity Severity, field string, message string) {
+			problems = append(problems, Problem{Severity: severity, Subject: "group", Uuid: group.Uuid, Name: group.Name, Field: field, Message: message})
+		}
+
+		switch {
+		case group.Uuid == "":
+			add(SeverityError, "uuid", "missing uuid")
+		case groupUuids[group.Uuid]:
+			add(SeverityError, "uuid", "duplicate uuid")
+		}
+
+		if group.Name == "" {
+			add(SeverityWarning, "name", "empty name")
+		}
+
+		groupUuids[group.Uuid] = true
+	}
+
+	var entryUuids map[string]bool = make(map[string]bool)
+
+	for _, entry := range db.Entries {
+		problems = append(problems, validateEntry(db, entry, entryUuids, groupUuids)...)
+	}
+
+	return problems
+}
+
+// validateEntry is a helper to check the entry's fields and group references.
+func validateEntry(db Db, entry Entry, entryUuids map[string]bool, groupUuids map[string]bool) []Problem {
+	var problems []Problem
+
+	var add = func(severity Severity, field string, message string) {
+		problems = append(problems, Problem{Severity: severity, Subject: "entry", Uuid: entry.Uuid, Name: entryName(entry), Field: field, Message: message})
+	}
+
+	switch {
+	case entry.Uuid == "":
+		add(SeverityError, "uuid", "missing uuid")
+	case entryUuids[entry.Uuid]:
+		add(SeverityError, "uuid", "duplicate uuid")
+	}
+
+	entryUuids[entry.Uuid] = true
+
+	if !slices.Contains(otpTypes, entry.Type) {
+		add(SeverityError, "type", fmt.Sprintf("unsupported otp type %q", entry.Type))
+	}
+
+	if !slices.Contains(otpAlgos, entry.Info.Algo) {
+		add(SeverityError, "info.algo", fmt.Sprintf("unsupported algo %q", entry.Info.Algo))
+	}
+
+	var info Info = entry.Info
+
+	switch entry.Type {
+	case "motp":
+		if _, err := hex.DecodeString(info.Secret); err != nil || info.Secret == "" {
+			add(SeverityError, "info.secret", "secret isn't valid hex")
+		}
+
+		if info.Pin == "" {
+			add(SeverityError, "info.pin", "missing pin")
+		}
+
+		// The code is taken from the hex encoded md5 digest
+		if info.Digits < 1 || info.Digits > 32 {
+			add(SeverityError, "info.digits", fmt.Sprintf("digits %v must be between 1 and 32", info.Digits))
+		}
+	default:
+		if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(info.Secret); err != nil || info.Secret == "" {
+			add(SeverityError, "info.secret", "secret isn't valid unpadded base32")
+		}
+
+		// The truncated hash is a 31 bit value
+		if info.Digits < 1 || info.Digits > 10 {
+			add(SeverityError, "info.digits", fmt.Sprintf("digits %v must be between 1 and 10", info.Digits))
+		}
+
+		if entry.Type == "steam" && info.Digits != 5 {
+			add(SeverityWarning, "info.digits", fmt.Sprintf("steam codes normally have 5 digits, not %v", info.Digits))
+		}
+	}
+
+	switch entry.Type {
+	case "hotp":
+		if info.Counter < 0 {
+			add(SeverityError, "info.counter", fmt.Sprintf("negative counter %v", info.Counter))
+		}
+	case "totp", "steam", "motp":
+		if info.Period <= 0 {
+			add(SeverityError, "info.period", fmt.Sprintf("period %v must be positive", info.Period))
+		}
+	}
+
+	if entry.Icon != "" {
+		if _, err := entry.DecodeIcon(); err != nil {
+			add(SeverityWarning, "icon", err.Error())
+		}
+	}
+
+	if db.Version >= 3 {
+		for _, uuid := range entry.Groups {
+			if !groupUuids[uuid] {
+				add(SeverityError, "groups", fmt.Sprintf("group %v doesn't exist", uuid))
+			}
+		}
+	} else if len(entry.Groups) > 0 {
+		add(SeverityWarning, "groups", fmt.Sprintf("group uuids are ignored by database version %v", db.Version))
+	}
+
+	return problems
+}
+
+// validateParams is a helper to check the AES-GCM nonce and tag.
+func validateParams(params Params) []Problem {
+	var problems []Problem
+
+	if msg := checkHex(params.Nonce, nonceLen); msg != "" {
+		problems = append(problems, Problem{Severity: SeverityError, Field: "nonce", Message: msg})
+	}
+
+	if msg := checkHex(params.Tag, tagLen); msg != "" {
+		problems = append(problems, Problem{Severity: SeverityError, Field: "tag", Message: msg})
+	}
+
+	return problems
+}
+
+// checkHex is a helper to check that the value is hex of the expected byte length.
+// It returns a description of the problem or an empty string.
+func checkHex(value string, length int) string {
+	data, err := hex.DecodeString(value)
+
+	switch {
+	case value == "":
+		return "missing value"
+	case err != nil:
+		return "value isn't valid hex"
+	case len(data) != length:
+		return fmt.Sprintf("value is %v bytes, expected %v", len(data), length)
+	}
+
+	return ""
+}
+
+// HasErrors reports whether any of the problems are errors.
+func HasErrors(problems []Problem) bool {
+	return slices.ContainsFunc(problems, func(p Problem) bool {
+		return p.Severity == SeverityError
+	})
+}
@@ -0,0 +1,60 @@
+package vault_test
+
+import (
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+func TestValidate(t *testing.T) {
+	for _, fixture := range fixtureVaults(t) {
+		if problems := vault.Validate(fixture.vault); vault.HasErrors(problems) {
+			t.Fatalf("[%v] Validate() = %v; want no errors", fixture.path, problems)
+		}
+	}
+
+	var vaultData *vault.Vault = readVault(t, "../test/data/aegis_plain_grouped_v3.json")
+	var entries []vault.Entry = vaultData.Db.Entries
+
+	entries[0].Info.Secret = "not base32!"
+	entries[1].Info.Digits = 0
+	entries[2].Groups = []string{"00000000-0000-0000-0000-000000000000"}
+	entries[3].Uuid = entries[4].Uuid
+
+	var want map[string]string = map[string]string{
+		entries[0].Uuid: "info.secret",
+		entries[1].Uuid: "info.digits",
+		entries[2].Uuid: "groups",
+		entries[4].Uuid: "uuid",
+	}
+
+	var problems []vault.Problem = vault.Validate(vaultData)
+
+	if len(problems) != len(want) {
+		t.Fatalf("Validate() = %v; want %v problems", problems, len(want))
+	}
+
+	for _, problem := range problems {
+		if problem.Severity != vault.SeverityError || want[problem.Uuid] != problem.Field {
+			t.Fatalf("Validate() = %v; want an error for field %q", problem, want[problem.Uuid])
+		}
+	}
+}
+
+func TestValidateHeader(t *testing.T) {
+	for _, fixture := range fixtureVaults(t) {
+		if len(fixture.vault.Header.Slots) == 0 {
+			continue
+		}
+
+		if problems := vault.ValidateHeader(fixture.vault.Header); len(problems) > 0 {
+			t.Fatalf("[%v] ValidateHeader() = %v; want no problems", fixture.path, problems)
+		}
+	}
+
+	var header vault.Header = vault.Header{Slots: []vault.Slot{{Type: 1, N: 1000, R: 8, P: 1}}}
+
+	if problems := vault.ValidateHeader(header); !vault.HasErrors(problems) {
+		t.Fatalf("ValidateHeader() = %v; want errors", problems)
+	}
+}
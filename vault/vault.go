@@ -3,24 +3,37 @@
 package vault
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 )
 
+const redactedText string = "<redacted>" // Replaces secret values in redacted output
+
 type Vault struct {
 	Version int    `json:"version"`
 	Header  Header `json:"header"`
 	Db      Db     `json:"db"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
 type VaultEncrypted struct {
 	Version int    `json:"version"`
 	Header  Header `json:"header"`
 	Db      string `json:"db"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
 type Header struct {
 	Slots  []Slot `json:"slots"`
 	Params Params `json:"params"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
 type Slot struct {
@@ -34,17 +47,26 @@ type Slot struct {
 	Salt      string `json:"salt"`
 	Repaired  bool   `json:"repaired"`
 	IsBackup  bool   `json:"is_backup"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
 type Params struct {
 	Nonce string `json:"nonce"`
 	Tag   string `json:"tag"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
 type Db struct {
 	Version int     `json:"version"`
 	Entries []Entry `json:"entries"`
-	Groups  []Group `json:"groups"`
+	Groups  []Group `json:"groups,omitzero"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
 type Entry struct {
@@ -58,7 +80,11 @@ type Entry struct {
 	IconHash string   `json:"icon_hash"`
 	Favorite bool     `json:"favorite"`
 	Info     Info     `json:"info"`
-	Groups   []string `json:"groups"`
+	Group    string   `json:"group,omitempty"`
+	Groups   []string `json:"groups,omitzero"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
 type Info struct {
@@ -68,38 +94,99 @@ type Info struct {
 	Period  int    `json:"period,omitempty"`
 	Counter int    `json:"counter,omitempty"`
 	Pin     string `json:"pin,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
 type Group struct {
 	Uuid string `json:"uuid"`
 	Name string `json:"name"`
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown members preserved when re-encoding
+	shape memberShape                // Known members that were null or absent
 }
 
+// The String methods mask secrets, pins, and slot keys so vaults can be printed
+// or logged safely. The Reveal methods include them and should only be used
+// when the output is meant to contain secrets.
+
 func (v *Vault) String() string {
-	return fmt.Sprintf("Vault{ version: %v, header: %v, db: %v }", v.Version, v.Header, v.Db)
+	return v.format(false)
+}
+
+// Reveal returns the vault's description including secrets and slot keys.
+func (v *Vault) Reveal() string {
+	return v.format(true)
+}
+
+// format is a helper to describe the vault, masking secrets unless revealed.
+func (v *Vault) format(reveal bool) string {
+	return fmt.Sprintf("Vault{ version: %v, header: %v, db: %v }", v.Version, v.Header.format(reveal), v.Db.format(reveal))
 }
 
 func (v *VaultEncrypted) String() string {
-	return fmt.Sprintf("Vault{ version: %v, header: %v, db: %v }", v.Version, v.Header, v.Db)
+	return v.format(false)
+}
+
+// Reveal returns the encrypted vault's description including slot keys.
+func (v *VaultEncrypted) Reveal() string {
+	return v.format(true)
+}
+
+// format is a helper to describe the encrypted vault, masking slot keys unless revealed.
+func (v *VaultEncrypted) format(reveal bool) string {
+	return fmt.Sprintf("Vault{ version: %v, header: %v, db: %v }", v.Version, v.Header.format(reveal), v.Db)
 }
 
 func (h Header) String() string {
-	return fmt.Sprintf("Header{ slots: %v, params: %v }", h.Slots, h.Params)
+	return h.format(false)
+}
+
+// Reveal returns the header's description including slot keys.
+func (h Header) Reveal() string {
+	return h.format(true)
+}
+
+// format is a helper to describe the header, masking slot keys unless revealed.
+func (h Header) format(reveal bool) string {
+	var slots []string
+
+	for _, slot := range h.Slots {
+		slots = append(slots, slot.format(reveal))
+	}
+
+	return fmt.Sprintf("Header{ slots: %v, params: %v }", formatList(slots), h.Params)
 }
 
 func (s Slot) String() string {
+	return s.format(false)
+}
+
+// GoString masks the slot's key and salt when formatted with %#v.
+func (s Slot) GoString() string {
+	return s.format(false)
+}
+
+// Reveal returns the slot's description including its key and salt.
+func (s Slot) Reveal() string {
+	return s.format(true)
+}
+
+// format is a helper to describe the slot, masking its key and salt unless revealed.
+func (s Slot) format(reveal bool) string {
 	var outputFormat string = "Slot{ type: %v, uuid: %v, key: %v, keyParams: %v, "
 	outputFormat += "n: %v, r: %v, p: %v, salt: %v, repaired: %v, isBackup: %v }"
 
 	var fields []any = []any{
 		s.Type,
 		s.Uuid,
-		s.Key,
+		mask(s.Key, reveal),
 		s.KeyParams,
 		s.N,
 		s.R,
 		s.P,
-		s.Salt,
+		mask(s.Salt, reveal),
 		s.Repaired,
 		s.IsBackup,
 	}
@@ -112,13 +199,39 @@ func (p Params) String() string {
 }
 
 func (d Db) String() string {
-	return fmt.Sprintf("Db{ version: %v, entries: %v, groups: %v}", d.Version, d.Entries, d.Groups)
+	return d.format(false)
+}
+
+// Reveal returns the database's description including entry secrets and pins.
+func (d Db) Reveal() string {
+	return d.format(true)
+}
+
+// format is a helper to describe the database, masking secrets unless revealed.
+func (d Db) format(reveal bool) string {
+	var entries []string
+
+	for _, entry := range d.Entries {
+		entries = append(entries, entry.format(reveal))
+	}
+
+	return fmt.Sprintf("Db{ version: %v, entries: %v, groups: %v}", d.Version, formatList(entries), d.Groups)
 }
 
 func (e Entry) String() string {
+	return e.format(false)
+}
+
+// Reveal returns the entry's description including its secret and pin.
+func (e Entry) Reveal() string {
+	return e.format(true)
+}
+
+// format is a helper to describe the entry, masking its secret and pin unless revealed.
+func (e Entry) format(reveal bool) string {
 	var outputFormat string = "Entry{ type: %v, uuid: %v, name: %v, issuer: %v, note: %v, "
 	outputFormat += "icon: %v, iconMime: %v, iconHash: %v, favorite: %v, "
-	outputFormat += "info: %v, groups: %v }"
+	outputFormat += "info: %v, group: %v, groups: %v }"
 
 	var fields []any = []any{
 		e.Type,
@@ -130,22 +243,46 @@ func (e Entry) String() string {
 		e.IconMime,
 		e.IconHash,
 		e.Favorite,
-		e.Info,
+		e.Info.format(reveal),
+		e.Group,
 		e.Groups,
 	}
 
 	return fmt.Sprintf(outputFormat, fields...)
 }
 
+// Redacted returns a copy of the entry with the secret and pin replaced.
+func (e Entry) Redacted() Entry {
+	e.Info.Secret = mask(e.Info.Secret, false)
+	e.Info.Pin = mask(e.Info.Pin, false)
+
+	return e
+}
+
 func (i Info) String() string {
+	return i.format(false)
+}
+
+// GoString masks the secret and pin when formatted with %#v.
+func (i Info) GoString() string {
+	return i.format(false)
+}
+
+// Reveal returns the info's description including its secret and pin.
+func (i Info) Reveal() string {
+	return i.format(true)
+}
+
+// format is a helper to describe the info, masking its secret and pin unless revealed.
+func (i Info) format(reveal bool) string {
 	var outputFormat string = "Info{ secret: %v, algo: %v, digits: %v, period: %v, counter: %v"
 
-	var fields []any = []any{i.Secret, i.Algo, i.Digits, i.Period, i.Counter}
+	var fields []any = []any{mask(i.Secret, reveal), i.Algo, i.Digits, i.Period, i.Counter}
 
 	// If the pin is included, add it to the formatted output and field data
 	if i.Pin != "" {
-		outputFormat += "pin: %v"
-		fields = append(fields, i.Pin)
+		outputFormat += ", pin: %v"
+		fields = append(fields, mask(i.Pin, reveal))
 	}
 
 	outputFormat += " }"
@@ -156,3 +293,17 @@ func (i Info) String() string {
 func (g Group) String() string {
 	return fmt.Sprintf("Group{ uuid: %v, name: %v }", g.Uuid, g.Name)
 }
+
+// mask is a helper to replace a non-empty secret value unless it's revealed.
+func mask(value string, reveal bool) string {
+	if reveal || value == "" {
+		return value
+	}
+
+	return redactedText
+}
+
+// formatList is a helper to format descriptions like fmt formats a slice.
+func formatList(items []string) string {
+	return "[" + strings.Join(items, " ") + "]"
+}
@@ -0,0 +1,225 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+	"strconv"
+)
+
+// Wipe overwrites the bytes with zeros so key material
+// doesn't linger in memory after it's used.
+func Wipe(b []byte) {
+	clear(b)
+	runtime.KeepAlive(b)
+}
+
+// EntrySecret holds the decoded secret and PIN of an entry
+// along with the parameters needed to generate its codes.
+type EntrySecret struct {
+	Uuid   string
+	Type   string
+	Algo   string
+	Digits int
+	Period int
+	Secret []byte // The base32 or hex decoded secret
+	Pin    []byte
+}
+
+// Wipe overwrites the entry's secret and PIN.
+func (s *EntrySecret) Wipe() {
+	Wipe(s.Secret)
+	Wipe(s.Pin)
+}
+
+// WipeSecrets overwrites the secrets and PINs of every entry.
+func WipeSecrets(secrets []EntrySecret) {
+	for i := range secrets {
+		secrets[i].Wipe()
+	}
+}
+
+// DecryptMetadata decrypts the vault's contents and returns a plaintext vault
+// whose entries have empty secrets and PINs.
+//
+// The secrets are blanked in the decrypted json before it's parsed,
+// so they're never copied into strings, and the json is wiped before returning.
+func (vaultData *VaultEncrypted) DecryptMetadata(masterKey []byte) (*Vault, error) {
+	content, err := vaultData.DecryptContents(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	defer Wipe(content)
+
+	blankSecrets(content)
+
+	var db Db
+
+	if err := json.Unmarshal(content, &db); err != nil {
+		return nil, err
+	}
+
+	var vaultDataPlain Vault = Vault{
+		Version: vaultData.Version,
+		Header:  vaultData.Header,
+		Db:      db,
+		Extra:   vaultData.Extra,
+	}
+
+	return &vaultDataPlain, nil
+}
+
+// DecryptSecrets decrypts the vault's contents and returns the decoded secrets
+// of its entries. The decrypted json is wiped before returning.
+//
+// The caller should wipe the secrets with WipeSecrets once the codes are generated.
+func (vaultData *VaultEncrypted) DecryptSecrets(masterKey []byte) ([]EntrySecret, error) {
+	content, err := vaultData.DecryptContents(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	defer Wipe(content)
+
+	// Secrets are kept as raw json so they can be wiped
+	var db struct {
+		Entries []struct {
+			Uuid string `json:"uuid"`
+			Type string `json:"type"`
+			Info struct {
+				Secret json.RawMessage `json:"secret"`
+				Pin    json.RawMessage `json:"pin"`
+				Algo   string          `json:"algo"`
+				Digits int             `json:"digits"`
+				Period int             `json:"period"`
+			} `json:"info"`
+		} `json:"entries"`
+	}
+
+	if err := json.Unmarshal(content, &db); err != nil {
+		return nil, err
+	}
+
+	var secrets []EntrySecret
+
+	defer func() {
+		for _, entry := range db.Entries {
+			Wipe(entry.Info.Secret)
+			Wipe(entry.Info.Pin)
+		}
+	}()
+
+	for _, entry := range db.Entries {
+		var secret EntrySecret = EntrySecret{
+			Uuid:   entry.Uuid,
+			Type:   entry.Type,
+			Algo:   entry.Info.Algo,
+			Digits: entry.Info.Digits,
+			Period: entry.Info.Period,
+			Pin:    unquote(entry.Info.Pin),
+		}
+
+		var raw []byte = unquote(entry.Info.Secret)
+
+		if entry.Type == "motp" {
+			secret.Secret = make([]byte, hex.DecodedLen(len(raw)))
+			_, err = hex.Decode(secret.Secret, raw)
+		} else {
+			var encoding *base32.Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+			secret.Secret = make([]byte, encoding.DecodedLen(len(raw)))
+
+			var n int
+
+			n, err = encoding.Decode(secret.Secret, raw)
+			secret.Secret = secret.Secret[:n]
+		}
+
+		Wipe(raw)
+
+		if err != nil {
+			secret.Wipe()
+			WipeSecrets(secrets)
+
+			return nil, err
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, nil
+}
+
+// unquote is a helper to copy the contents of a raw json string.
+// Secrets are base32, hex, or digits so they don't contain escapes.
+func unquote(raw json.RawMessage) []byte {
+	if len(raw) < 2 || raw[0] != '"' {
+		return nil
+	}
+
+	return bytes.Clone(raw[1 : len(raw)-1])
+}
+
+// blankSecrets is a helper to overwrite the values of the secret and pin members
+// in the json with spaces, leaving empty strings in their place.
+func blankSecrets(content []byte) {
+	for _, key := range [][]byte{[]byte(strconv.Quote("secret")), []byte(strconv.Quote("pin"))} {
+		for i := 0; ; {
+			var j int = bytes.Index(content[i:], key)
+			if j < 0 {
+				break
+			}
+
+			i += j + len(key)
+
+			// A key is followed by a colon, unlike a string value that happens to match
+			var k int = skipSpace(content, i)
+			if k >= len(content) || content[k] != ':' {
+				continue
+			}
+
+			k = skipSpace(content, k+1)
+			if k >= len(content) || content[k] != '"' {
+				continue
+			}
+
+			var end int = stringEnd(content, k)
+
+			// Keep the opening quote and move the closing quote next to it
+			content[k+1] = '"'
+
+			for n := k + 2; n <= end; n++ {
+				content[n] = ' '
+			}
+
+			i = end
+		}
+	}
+}
+
+// skipSpace is a helper to find the index of the next non-whitespace byte.
+func skipSpace(content []byte, i int) int {
+	for i < len(content) && (content[i] == ' ' || content[i] == '\t' || content[i] == '\n' || content[i] == '\r') {
+		i++
+	}
+
+	return i
+}
+
+// stringEnd is a helper to find the index of the closing quote
+// of the json string starting at i.
+func stringEnd(content []byte, i int) int {
+	for i++; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return len(content) - 1
+}
@@ -0,0 +1,99 @@
+package vault_test
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+// readEncrypted is a helper to read an encrypted fixture and find its master key.
+func readEncrypted(t *testing.T, filePath string) (*vault.VaultEncrypted, []byte) {
+	t.Helper()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vaultDataEnc vault.VaultEncrypted
+
+	if err := json.Unmarshal(data, &vaultDataEnc); err != nil {
+		t.Fatal(err)
+	}
+
+	masterKey, err := vaultDataEnc.FindMasterKeyBytes([]byte("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &vaultDataEnc, masterKey
+}
+
+func TestDecryptMetadata(t *testing.T) {
+	for _, path := range encryptedFixtures {
+		vaultDataEnc, masterKey := readEncrypted(t, path)
+
+		plain, err := vaultDataEnc.DecryptVault(masterKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		meta, err := vaultDataEnc.DecryptMetadata(masterKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, entry := range meta.Db.Entries {
+			var want vault.Entry = plain.Db.Entries[i]
+			want.Info.Secret, want.Info.Pin = "", ""
+
+			if entry.Info.Secret != "" || entry.Info.Pin != "" || entry.String() != want.String() {
+				t.Fatalf("[%v] DecryptMetadata() = %v; want %v", i, entry, want)
+			}
+		}
+	}
+}
+
+func TestDecryptSecrets(t *testing.T) {
+	for _, path := range encryptedFixtures {
+		vaultDataEnc, masterKey := readEncrypted(t, path)
+
+		plain, err := vaultDataEnc.DecryptVault(masterKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		secrets, err := vaultDataEnc.DecryptSecrets(masterKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, secret := range secrets {
+			var entry vault.Entry = plain.Db.Entries[i]
+			var want []byte
+
+			if entry.Type == "motp" {
+				want, _ = hex.DecodeString(entry.Info.Secret)
+			} else {
+				want, _ = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(entry.Info.Secret)
+			}
+
+			if secret.Uuid != entry.Uuid || !slices.Equal(secret.Secret, want) || string(secret.Pin) != entry.Info.Pin {
+				t.Fatalf("[%v] DecryptSecrets() = %v; want secret for %v", i, secret.Uuid, entry.Uuid)
+			}
+		}
+
+		vault.WipeSecrets(secrets)
+
+		for i, secret := range secrets {
+			if slices.ContainsFunc(secret.Secret, func(b byte) bool { return b != 0 }) {
+				t.Fatalf("[%v] WipeSecrets() left secret bytes", i)
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package avdu
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sammy-t/avdu/vault"
+)
+
+const defWatchInterval time.Duration = 2 * time.Second // The default directory polling interval
+
+// WatchEvent describes a reload of the vault directory's selected vault file.
+//
+// If the reload failed, Err is set and the previously loaded vault
+// remains current.
+type WatchEvent struct {
+	Path  string
+	Vault *vault.Vault
+	Diff  vault.DiffResult
+	Err   error
+}
+
+// Watcher polls a vault directory and reloads the selected
+// vault file whenever a new or changed backup or export file appears.
+//
+// Encrypted vaults are decrypted again using the cached master key,
+// falling back to the password if the master key no longer matches.
+type Watcher struct {
+	dir      string
+	interval time.Duration
+	policy   SelectPolicy
+	index    int
+	reader   *keyCache
+
+	mu      sync.Mutex // Guards the fields below, which are updated by each poll
+	path    string
+	modTime time.Time
+	size    int64
+	current *vault.Vault
+	lastErr string // The last error finding the vault file, which isn't sent again
+
+	events   chan WatchEvent
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher creates a watcher for the vault directory.
+//
+// The password is only used for encrypted vault files. Without one,
+// encrypted files are reported with ErrPasswordRequired until SetPassword is called.
+// A non-positive interval uses the default polling interval.
+func NewWatcher(vaultDir string, pwd string, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = defWatchInterval
+	}
+
+	return &Watcher{
+		dir:      vaultDir,
+		interval: interval,
+		policy:   SelectFilenameTime,
+		reader:   &keyCache{pwd: pwd},
+		events:   make(chan WatchEvent),
+		done:     make(chan struct{}),
+	}
+}
+
+// SetSelection sets the policy used to choose the vault file
+// from the directory. The index is only used by the SelectIndex policy.
+func (w *Watcher) SetSelection(policy SelectPolicy, index int) {
+	w.policy = policy
+	w.index = index
+}
+
+// SetPassword sets the password used for encrypted vault files,
+// such as after an event reported ErrPasswordRequired.
+//
+// The password is checked against the current vault file, which is read
+// again on the next poll if it's encrypted. The error is returned if the
+// password can't decrypt it.
+func (w *Watcher) SetPassword(pwd string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.path != "" {
+		var reader *keyCache = &keyCache{pwd: pwd}
+
+		if _, err := reader.read(w.path); err != nil {
+			return err
+		}
+
+		if reader.masterKey != nil {
+			w.reader.setKey(reader.masterKey)
+		}
+
+		w.path = "" // Read the file again with the password
+	}
+
+	w.reader.pwd = pwd
+
+	return nil
+}
+
+// Load reads the directory's selected vault file
+// and returns the plaintext vault.
+//
+// Load should be called before Start to establish the
+// vault that later changes are compared against.
+func (w *Watcher) Load() (string, *vault.Vault, error) {
+	w.mu.Lock()
+	w.lastErr = "" // Return the error even if it was already sent
+	w.mu.Unlock()
+
+	event, changed := w.poll()
+	if !changed {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		return w.path, w.current, nil
+	}
+
+	return event.Path, event.Vault, event.Err
+}
+
+// Events returns the channel that receives an event
+// for each reload of the vault directory.
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Start polls the vault directory in a new goroutine
+// until Stop is called.
+func (w *Watcher) Start() {
+	go w.run()
+}
+
+// Stop stops polling the vault directory and wipes the cached master key.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		w.reader.wipe()
+	})
+}
+
+// run is a helper to poll the directory on each tick
+// and send the resulting events.
+func (w *Watcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		event, changed := w.poll()
+		if !changed {
+			continue
+		}
+
+		select {
+		case w.events <- event:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// poll is a helper to check the directory for a new or modified vault file
+// and reload it. It reports whether an event should be emitted.
+func (w *Watcher) poll() (WatchEvent, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Don't cache a master key again once Stop has wiped it
+	select {
+	case <-w.done:
+		return WatchEvent{}, false
+	default:
+	}
+
+	vaultPath, err := FindVaultPathWith(w.dir, w.policy, w.index)
+	if err != nil {
+		return w.pollErr(WatchEvent{Err: err})
+	}
+
+	info, err := os.Stat(vaultPath)
+	if err != nil {
+		return w.pollErr(WatchEvent{Path: vaultPath, Err: err})
+	}
+
+	w.lastErr = ""
+
+	// Skip files that have already been read
+	if vaultPath == w.path && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return WatchEvent{}, false
+	}
+
+	w.path = vaultPath
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+
+	vaultData, err := w.reader.read(vaultPath)
+	if err != nil {
+		return WatchEvent{Path: vaultPath, Err: err}, true
+	}
+
+	var diff vault.DiffResult = vault.Diff(w.current, vaultData)
+
+	w.current = vaultData
+
+	return WatchEvent{Path: vaultPath, Vault: vaultData, Diff: diff}, true
+}
+
+// pollErr is a helper to report the event's error
+// only if it differs from the last error, so one that persists
+// across polls isn't sent on every tick.
+func (w *Watcher) pollErr(event WatchEvent) (WatchEvent, bool) {
+	var message string = event.Path + ": " + event.Err.Error()
+
+	if message == w.lastErr {
+		return WatchEvent{}, false
+	}
+
+	w.lastErr = message
+
+	return event, true
+}
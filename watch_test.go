@@ -0,0 +1,191 @@
+package avdu_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sammy-t/avdu"
+)
+
+const watchInterval time.Duration = 10 * time.Millisecond
+
+func TestWatcher(t *testing.T) {
+	var dir string = t.TempDir()
+	var oldPath string = filepath.Join(dir, "aegis-backup-20240625-000001.json")
+
+	writeFixture(t, "test/data/aegis_plain.json", oldPath)
+
+	var watcher *avdu.Watcher = avdu.NewWatcher(dir, "test", watchInterval)
+
+	loadedPath, loaded, err := watcher.Load()
+	if err != nil || loadedPath != oldPath || len(loaded.Db.Entries) != 7 {
+		t.Fatalf("Load() = %v, %v; want %v, nil", loadedPath, err, oldPath)
+	}
+
+	watcher.Start()
+	defer watcher.Stop()
+
+	// A newer backup is reloaded and compared against the loaded vault
+	var newPath string = filepath.Join(dir, "aegis-backup-20240625-000002.json")
+
+	dropBackup(t, "test/data/aegis_plain_grouped_v3.json", newPath)
+
+	event := nextEvent(t, watcher)
+
+	if event.Err != nil || event.Path != newPath || len(event.Vault.Db.Groups) != 2 {
+		t.Fatalf("Events() = %v, %v; want the vault at %v", event.Path, event.Err, newPath)
+	}
+
+	if len(event.Diff.Changed) != 4 || len(event.Diff.AddedGroups) != 2 {
+		t.Fatalf("Events() diff = %v; want 4 changed entries and 2 added groups", event.Diff)
+	}
+
+	// Encrypted backups are decrypted with the password
+	var encPath string = filepath.Join(dir, "aegis-backup-20240625-000003.json")
+
+	dropBackup(t, "test/data/aegis_encrypted.json", encPath)
+
+	event = nextEvent(t, watcher)
+
+	if event.Err != nil || event.Path != encPath || len(event.Vault.Db.Entries) != 7 {
+		t.Fatalf("Events() = %v, %v; want the vault at %v", event.Path, event.Err, encPath)
+	}
+
+	// A corrupt backup reports an error for its path
+	var corruptPath string = filepath.Join(dir, "aegis-backup-20240625-000004.json")
+
+	if err := os.WriteFile(corruptPath+".tmp", []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Rename(corruptPath+".tmp", corruptPath); err != nil {
+		t.Fatal(err)
+	}
+
+	event = nextEvent(t, watcher)
+
+	if event.Err == nil || event.Path != corruptPath || event.Vault != nil {
+		t.Fatalf("Events() = %v, %v; want an error for %v", event.Path, event.Err, corruptPath)
+	}
+
+	// The corrupt file isn't reported again until it changes
+	select {
+	case event := <-watcher.Events():
+		t.Fatalf("Events() = %v, %v; want no event for an unchanged file", event.Path, event.Err)
+	case <-time.After(10 * watchInterval):
+	}
+}
+
+func TestWatcherPassword(t *testing.T) {
+	var dir string = t.TempDir()
+
+	writeFixture(t, "test/data/aegis_plain.json", filepath.Join(dir, "aegis-backup-20240625-000001.json"))
+
+	var watcher *avdu.Watcher = avdu.NewWatcher(dir, "", watchInterval)
+
+	if _, _, err := watcher.Load(); err != nil {
+		t.Fatalf("Load() = %v; want nil", err)
+	}
+
+	watcher.Start()
+	defer watcher.Stop()
+
+	// An encrypted backup appearing without a password is reported once
+	var encPath string = filepath.Join(dir, "aegis-backup-20240625-000002.json")
+
+	dropBackup(t, "test/data/aegis_encrypted.json", encPath)
+
+	event := nextEvent(t, watcher)
+
+	if !errors.Is(event.Err, avdu.ErrPasswordRequired) || event.Path != encPath {
+		t.Fatalf("Events() = %v, %v; want %v for %v", event.Path, event.Err, avdu.ErrPasswordRequired, encPath)
+	}
+
+	select {
+	case event := <-watcher.Events():
+		t.Fatalf("Events() = %v, %v; want no event until the password is set", event.Path, event.Err)
+	case <-time.After(10 * watchInterval):
+	}
+
+	if err := watcher.SetPassword("wrong"); err == nil {
+		t.Fatal("SetPassword() with the wrong password = nil; want error")
+	}
+
+	if err := watcher.SetPassword("test"); err != nil {
+		t.Fatalf("SetPassword() = %v; want nil", err)
+	}
+
+	// The encrypted backup is read again with the password
+	event = nextEvent(t, watcher)
+
+	if event.Err != nil || event.Path != encPath || len(event.Vault.Db.Entries) != 7 {
+		t.Fatalf("Events() = %v, %v; want the vault at %v", event.Path, event.Err, encPath)
+	}
+}
+
+func TestWatcherUnreadable(t *testing.T) {
+	var dir string = filepath.Join(t.TempDir(), "missing")
+	var watcher *avdu.Watcher = avdu.NewWatcher(dir, "", watchInterval)
+
+	if _, _, err := watcher.Load(); err == nil {
+		t.Fatal("Load() of a missing directory = nil; want error")
+	}
+
+	watcher.Start()
+	defer watcher.Stop()
+
+	// The same error isn't sent on every poll
+	select {
+	case event := <-watcher.Events():
+		t.Fatalf("Events() = %v, %v; want no event for a repeated error", event.Path, event.Err)
+	case <-time.After(10 * watchInterval):
+	}
+
+	// The directory appears with its backup in one step
+	var tmpDir string = dir + ".tmp"
+
+	if err := os.Mkdir(tmpDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFixture(t, "test/data/aegis_plain.json", filepath.Join(tmpDir, "aegis-backup-20240625-000001.json"))
+
+	if err := os.Rename(tmpDir, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var vaultPath string = filepath.Join(dir, "aegis-backup-20240625-000001.json")
+
+	if event := nextEvent(t, watcher); event.Err != nil || event.Path != vaultPath {
+		t.Fatalf("Events() = %v, %v; want the vault at %v", event.Path, event.Err, vaultPath)
+	}
+}
+
+// dropBackup is a helper to copy the fixture to the path in one step
+// so the watcher never reads a partially written file.
+func dropBackup(t *testing.T, fixture string, filePath string) {
+	t.Helper()
+
+	writeFixture(t, fixture, filePath+".tmp")
+
+	if err := os.Rename(filePath+".tmp", filePath); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// nextEvent is a helper to wait for the watcher's next event.
+func nextEvent(t *testing.T, watcher *avdu.Watcher) avdu.WatchEvent {
+	t.Helper()
+
+	select {
+	case event := <-watcher.Events():
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Events() sent no event")
+	}
+
+	return avdu.WatchEvent{}
+}